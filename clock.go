@@ -0,0 +1,185 @@
+package gocron
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of a scheduler. The default clock is the system clock.
+// Tests can pass a `FakeClock` to `WithClock` to run jobs without waiting for them
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// NewTimer creates a timer sending the current time on its channel after `d`
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a `Clock`, see `time.Timer`
+type Timer interface {
+	// C returns the channel the time is sent on when the timer fires
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It returns false if the timer already fired or was stopped
+	Stop() bool
+
+	// Reset changes the timer to fire after `d`. It returns true if the timer was active
+	Reset(d time.Duration) bool
+}
+
+// systemClock is the `Clock` of the `time` package
+type systemClock struct{}
+
+// Now returns `time.Now()`
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer returns a `time.Timer`
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+// systemTimer is a `time.Timer`
+type systemTimer struct {
+	*time.Timer
+}
+
+// C returns the channel of the timer
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// FakeClock is a `Clock` that only moves when it is told to, for tests.
+// Its timers fire when the clock is advanced past their deadline
+//
+// Example
+//
+//  // ...
+//	clock := NewFakeClock(time.Date(2016, 1, 6, 10, 0, 0, 0, time.Local))
+//	s := NewScheduler(WithClock(clock))
+//	s.Every(1).Hour().Do(task)
+//	s.RunPending()            // initializes the job
+//	clock.Advance(time.Hour)
+//	s.RunPending()            // runs the task
+//
+type FakeClock struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock creates a fake clock set to `now`
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mutex)
+	return c
+}
+
+// Now returns the time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// NewTimer creates a timer firing once the clock is advanced by `d`
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by `d`, and fires the timers whose deadline is reached
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set sets the time of the clock, and fires the timers whose deadline is reached
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = now
+	timers := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(now) {
+			timers = append(timers, t)
+		} else {
+			t.fire(now)
+		}
+	}
+	c.timers = timers
+	c.cond.Broadcast()
+}
+
+// BlockUntil blocks until at least `n` timers of the clock are waiting to fire.
+// It lets tests wait for a started scheduler to go back to sleep
+func (c *FakeClock) BlockUntil(n int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// fakeTimer is a timer of a `FakeClock`
+type fakeTimer struct {
+	clock    *FakeClock
+	c        chan time.Time
+	deadline time.Time
+}
+
+// C returns the channel of the timer
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop removes the timer from the timers of the clock
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	return t.stop()
+}
+
+// Reset sets the deadline of the timer, firing it right away if it is reached
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	active := t.stop()
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+	} else {
+		c.timers = append(c.timers, t)
+	}
+	c.cond.Broadcast()
+	return active
+}
+
+// stop removes the timer from the timers of the clock. The clock lock must be held
+func (t *fakeTimer) stop() bool {
+	c := t.clock
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fire sends the time on the channel of the timer, unless the previous time wasn't received
+func (t *fakeTimer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
@@ -0,0 +1,256 @@
+// Package config loads the jobs of a gocron scheduler from a YAML, JSON or TOML file.
+// The jobs run tasks registered by `gocron.RegisterTask`, and their schedule is a spec
+// parsed by `gocron.Job.Spec`. `Watch` reconciles the scheduler with the file whenever it changes.
+//
+// Example
+//
+//  // jobs.yaml
+//	jobs:
+//	  - name: backup
+//	    schedule: every 1 days at 2:00
+//	    timezone: Asia/Taipei
+//	    task: backup
+//	    args: [/var/data, 3]
+//	    timeout: 30m
+//	    tags: [nightly]
+//	  - name: report
+//	    schedule: cron 0 9 * * MON-FRI
+//	    task: report
+//	    enabled: false
+//
+//  // main.go
+//	gocron.RegisterTask("backup", backup)
+//	gocron.RegisterTask("report", report)
+//	cfg, err := config.Load("jobs.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if _, err := cfg.Apply(s); err != nil {
+//		log.Fatal(err) // lists every invalid job
+//	}
+//
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/taka-wang/gocron"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format of a config file
+type Format string
+
+const (
+	// YAML is the format of the .yaml and .yml files
+	YAML Format = "yaml"
+
+	// JSON is the format of the .json files
+	JSON Format = "json"
+
+	// TOML is the format of the .toml files
+	TOML Format = "toml"
+)
+
+// ErrFormatNotSupported is the error returned for the files whose format isn't YAML, JSON or TOML
+var ErrFormatNotSupported = errors.New("the config format is not supported")
+
+// Config is the list of jobs of a config file
+type Config struct {
+	Jobs []Job `json:"jobs" yaml:"jobs" toml:"jobs"`
+}
+
+// Job is the config of a job
+type Job struct {
+	// Name is the name of the job, unique in the config
+	Name string `json:"name" yaml:"name" toml:"name"`
+
+	// Schedule is the schedule spec of the job, see `gocron.Job.Spec`
+	Schedule string `json:"schedule" yaml:"schedule" toml:"schedule"`
+
+	// Timezone is the name of the location of the job, e.g. "Asia/Taipei".
+	// The default location of the scheduler is used if it is empty
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty" toml:"timezone,omitempty"`
+
+	// Task is the name of the registered task of the job
+	Task string `json:"task" yaml:"task" toml:"task"`
+
+	// Args are the arguments of the task, converted to its parameters through JSON
+	Args []interface{} `json:"args,omitempty" yaml:"args,omitempty" toml:"args,omitempty"`
+
+	// Enabled is false if the job is paused. Jobs are enabled by default
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+
+	// Timeout is the maximum duration of a run, e.g. "30s", see `time.ParseDuration`
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+
+	// Tags are the labels of the job, see `gocron.Job.Tag`
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+}
+
+// Errors are the errors of the invalid jobs of a config. They match each of
+// the errors with `errors.Is`, e.g. `gocron.ErrSpecNotValid`
+type Errors []error
+
+// Error lists the errors, one per line
+func (e Errors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return fmt.Sprintf("gocron/config: %d invalid jobs:\n%s", len(e), strings.Join(lines, "\n"))
+}
+
+// Unwrap returns the errors
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Load reads the config file at `path`, in the format of its extension
+func Load(path string) (*Config, error) {
+	var format Format
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = YAML
+	case ".json":
+		format = JSON
+	case ".toml":
+		format = TOML
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrFormatNotSupported, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, format)
+}
+
+// Parse parses a config in the format. Unknown keys are errors in every format,
+// so a misspelled key isn't silently ignored
+func Parse(data []byte, format Format) (*Config, error) {
+	c := &Config{}
+	var err error
+	switch format {
+	case YAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(c); err == io.EOF {
+			// an empty file has no jobs
+			err = nil
+		}
+	case JSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+	case TOML:
+		var meta toml.MetaData
+		if meta, err = toml.Decode(string(data), c); err == nil && len(meta.Undecoded()) > 0 {
+			keys := make([]string, len(meta.Undecoded()))
+			for i, key := range meta.Undecoded() {
+				keys[i] = key.String()
+			}
+			err = fmt.Errorf("unknown keys %s", strings.Join(keys, ", "))
+		}
+	default:
+		err = fmt.Errorf("%w: %q", ErrFormatNotSupported, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Definitions returns the definitions of the jobs. It validates every job, and returns
+// the `Errors` of all of the invalid jobs, if any, with the definitions of the valid ones
+func (c *Config) Definitions() ([]gocron.JobDefinition, error) {
+	var defs []gocron.JobDefinition
+	var errs Errors
+	names := make(map[string]bool)
+	for i, job := range c.Jobs {
+		def, err := job.Definition()
+		if err == nil && names[job.Name] {
+			err = fmt.Errorf("the name is not unique")
+		}
+		if err == nil {
+			err = def.Validate()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("jobs[%d] %q: %w", i, job.Name, err))
+			continue
+		}
+		names[job.Name] = true
+		defs = append(defs, def)
+	}
+	if len(errs) > 0 {
+		return defs, errs
+	}
+	return defs, nil
+}
+
+// Definition returns the definition of the job, or an error if a field is missing or malformed
+func (job Job) Definition() (gocron.JobDefinition, error) {
+	def := gocron.JobDefinition{
+		Name:     job.Name,
+		Schedule: job.Schedule,
+		Location: job.Timezone,
+		Paused:   job.Enabled != nil && !*job.Enabled,
+		Tags:     job.Tags,
+	}
+	switch {
+	case job.Name == "":
+		return def, errors.New("the name is missing")
+	case job.Schedule == "":
+		return def, errors.New("the schedule is missing")
+	case job.Task == "":
+		return def, errors.New("the task is missing")
+	}
+	if job.Timeout != "" {
+		timeout, err := time.ParseDuration(job.Timeout)
+		if err != nil {
+			return def, fmt.Errorf("the timeout is not valid: %w", err)
+		}
+		def.Timeout = timeout
+	}
+
+	task := gocron.TaskDefinition{Name: job.Task}
+	for i, arg := range job.Args {
+		raw, err := json.Marshal(arg)
+		if err != nil {
+			return def, fmt.Errorf("argument %d is not valid: %w", i, err)
+		}
+		task.Args = append(task.Args, raw)
+	}
+	def.Tasks = []gocron.TaskDefinition{task}
+	return def, nil
+}
+
+// Apply adds the jobs to the scheduler, replacing the jobs with the same name like
+// `EveryWithName`. It validates every job first, and only changes the scheduler
+// if all of the jobs are valid. Otherwise it returns the `Errors` of the invalid jobs
+func (c *Config) Apply(s gocron.Scheduler) ([]*gocron.Job, error) {
+	defs, err := c.Definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*gocron.Job, 0, len(defs))
+	for _, def := range defs {
+		job, err := s.AddDefinition(def)
+		if err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
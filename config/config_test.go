@@ -0,0 +1,375 @@
+// Tests for the config loader of gocron
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/taka-wang/gocron"
+	"github.com/taka-wang/gocron/internal/sugar"
+)
+
+const yamlConfig = `
+jobs:
+  - name: backup
+    schedule: every 1 days at 2:00
+    timezone: Asia/Taipei
+    task: config-backup
+    args: [/var/data, 3]
+    timeout: 30m
+    tags: [nightly, storage]
+  - name: report
+    schedule: cron 0 9 * * MON-FRI
+    task: config-report
+    enabled: false
+`
+
+const jsonConfig = `{
+  "jobs": [
+    {
+      "name": "backup",
+      "schedule": "every 1 days at 2:00",
+      "timezone": "Asia/Taipei",
+      "task": "config-backup",
+      "args": ["/var/data", 3],
+      "timeout": "30m",
+      "tags": ["nightly", "storage"]
+    },
+    {"name": "report", "schedule": "cron 0 9 * * MON-FRI", "task": "config-report", "enabled": false}
+  ]
+}`
+
+const tomlConfig = `
+[[jobs]]
+name = "backup"
+schedule = "every 1 days at 2:00"
+timezone = "Asia/Taipei"
+task = "config-backup"
+args = ["/var/data", 3]
+timeout = "30m"
+tags = ["nightly", "storage"]
+
+[[jobs]]
+name = "report"
+schedule = "cron 0 9 * * MON-FRI"
+task = "config-report"
+enabled = false
+`
+
+// backups records the runs of the "config-backup" task
+var backups struct {
+	sync.Mutex
+	runs []string
+}
+
+// the registry is global and can't be reset, so the tasks are registered once,
+// and the tests reset their records instead
+func TestMain(m *testing.M) {
+	gocron.RegisterTask("config-backup", func(dir string, keep int) {
+		backups.Lock()
+		defer backups.Unlock()
+		backups.runs = append(backups.runs, fmt.Sprintf("%s %d", dir, keep))
+	})
+	gocron.RegisterTask("config-report", func() {})
+	gocron.RegisterTask("reload-task", func(name string) {})
+	os.Exit(m.Run())
+}
+
+func TestConfig(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Config loader")
+
+	dir, err := os.MkdirTemp("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s.Assert("the YAML, JSON and TOML configs are loaded alike", func(log sugar.Log) bool {
+		files := map[string]string{"jobs.yaml": yamlConfig, "jobs.json": jsonConfig, "jobs.toml": tomlConfig}
+		var expected []gocron.JobDefinition
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			os.WriteFile(path, []byte(content), 0644)
+			cfg, err := Load(path)
+			if err != nil {
+				log("%s: %v", name, err)
+				return false
+			}
+			defs, err := cfg.Definitions()
+			if err != nil {
+				log("%s: %v", name, err)
+				return false
+			}
+			if expected == nil {
+				expected = defs
+			}
+			if !reflect.DeepEqual(defs, expected) {
+				log("%s: expected %v, got %v", name, expected, defs)
+				return false
+			}
+		}
+		backup := expected[0]
+		return len(expected) == 2 && backup.Timeout == 30*time.Minute && backup.Location == "Asia/Taipei" &&
+			string(backup.Tasks[0].Args[1]) == "3" && expected[1].Paused
+	})
+
+	s.Assert("`Load(...)` rejects unknown formats", func(log sugar.Log) bool {
+		_, err := Load(filepath.Join(dir, "jobs.ini"))
+		return errors.Is(err, ErrFormatNotSupported)
+	})
+
+	s.Assert("`Parse(...)` rejects unknown keys in every format", func(log sugar.Log) bool {
+		configs := map[Format]string{
+			YAML: "jobs:\n  - name: backup\n    shedule: every 1 days\n",
+			JSON: `{"jobs": [{"name": "backup", "shedule": "every 1 days"}]}`,
+			TOML: "[[jobs]]\nname = \"backup\"\nshedule = \"every 1 days\"\n",
+		}
+		for format, content := range configs {
+			if _, err := Parse([]byte(content), format); err == nil || !strings.Contains(err.Error(), "shedule") {
+				log("%s: expected an error naming the unknown key, got %v", format, err)
+				return false
+			}
+		}
+		cfg, err := Parse(nil, YAML)
+		return err == nil && len(cfg.Jobs) == 0
+	})
+
+	s.Assert("`Apply(...)` adds the jobs to the scheduler", func(log sugar.Log) bool {
+		cfg, _ := Parse([]byte(yamlConfig), YAML)
+		scheduler := gocron.NewScheduler()
+		jobs, err := cfg.Apply(scheduler)
+		if err != nil || len(jobs) != 2 {
+			log("%v %v", jobs, err)
+			return false
+		}
+		backups.Lock()
+		backups.runs = nil
+		backups.Unlock()
+		scheduler.RunAll()
+
+		backups.Lock()
+		defer backups.Unlock()
+		log("%v", backups.runs)
+		return jobs[0].Name() == "backup" && reflect.DeepEqual(jobs[0].Tags(), []string{"nightly", "storage"}) &&
+			jobs[1].IsPaused() && len(backups.runs) == 1 && backups.runs[0] == "/var/data 3"
+	})
+
+	s.Assert("`Apply(...)` reports every invalid job and leaves the scheduler unchanged", func(log sugar.Log) bool {
+		cfg, err := Parse([]byte(`
+jobs:
+  - name: ok
+    schedule: every 1 hours
+    task: config-report
+  - schedule: every 1 hours
+    task: config-report
+  - name: spec
+    schedule: every 1 eons
+    task: config-report
+  - name: task
+    schedule: every 1 hours
+    task: config-unknown
+  - name: args
+    schedule: every 1 hours
+    task: config-backup
+    args: [1, 2]
+  - name: timezone
+    schedule: every 1 hours
+    timezone: Mars/Olympus
+    task: config-report
+  - name: timeout
+    schedule: every 1 hours
+    timeout: soon
+    task: config-report
+  - name: ok
+    schedule: every 2 hours
+    task: config-report
+`), YAML)
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		scheduler := gocron.NewScheduler()
+		_, err = cfg.Apply(scheduler)
+		log("%v", err)
+
+		var errs Errors
+		return errors.As(err, &errs) && len(errs) == 7 && len(scheduler.Jobs()) == 0 &&
+			errors.Is(err, gocron.ErrSpecNotValid) && errors.Is(err, gocron.ErrTaskNotRegistered) &&
+			errors.Is(err, gocron.ErrMissmatchedTaskParams)
+	})
+}
+
+// jobsByName returns the jobs of the scheduler by name
+func jobsByName(s gocron.Scheduler) map[string]*gocron.Job {
+	jobs := make(map[string]*gocron.Job)
+	for _, job := range s.Jobs() {
+		jobs[job.Name()] = job
+	}
+	return jobs
+}
+
+func TestReload(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Config reload")
+
+	dir, err := os.MkdirTemp("", "reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := func(jobs ...string) string {
+		content := "jobs:\n"
+		for _, job := range jobs {
+			content += fmt.Sprintf("  - {name: %s, task: reload-task, args: [%s], %s}\n", job[:1], job[:1], job[1:])
+		}
+		return content
+	}
+
+	s.Assert("`Reconcile(...)` adds, removes and updates the changed jobs only", func(log sugar.Log) bool {
+		cfg, _ := Parse([]byte(config("a schedule: every 1 hours", "b schedule: every 1 hours", "c schedule: every 1 hours")), YAML)
+		next, _ := Parse([]byte(config("b schedule: every 1 hours", "c schedule: every 2 hours", "d schedule: every 1 days")), YAML)
+		scheduler := gocron.NewScheduler()
+		if _, err := cfg.Apply(scheduler); err != nil {
+			log("%v", err)
+			return false
+		}
+		scheduler.PauseWithName("b")
+		before := jobsByName(scheduler)
+
+		diff, err := cfg.Reconcile(scheduler, next)
+		after := jobsByName(scheduler)
+		log("%v %v", diff, err)
+		return err == nil && diff.Changed() && diff.String() == "added d; removed a; updated c" &&
+			reflect.DeepEqual(diff.Unchanged, []string{"b"}) && len(after) == 3 && after["a"] == nil &&
+			after["b"] == before["b"] && after["b"].IsPaused() && after["c"] == before["c"] && after["d"] != nil
+	})
+
+	s.Assert("`Reconcile(...)` leaves the scheduler unchanged if a job is invalid", func(log sugar.Log) bool {
+		cfg, _ := Parse([]byte(config("a schedule: every 1 hours")), YAML)
+		next, _ := Parse([]byte(config("a schedule: every 1 eons", "b schedule: every 1 hours")), YAML)
+		scheduler := gocron.NewScheduler()
+		cfg.Apply(scheduler)
+		job := scheduler.Jobs()[0]
+
+		diff, err := cfg.Reconcile(scheduler, next)
+		def, _ := job.Definition()
+		log("%v %v", diff, err)
+		return errors.Is(err, gocron.ErrSpecNotValid) && !diff.Changed() && len(scheduler.Jobs()) == 1 &&
+			def.Schedule == "every 1 hours"
+	})
+
+	s.Assert("`Watch(...)` reloads the file when it changes, and on SIGHUP with `ReloadOnSIGHUP()`", func(log sugar.Log) bool {
+		path := filepath.Join(dir, "jobs.yaml")
+		os.WriteFile(path, []byte(config("a schedule: every 1 hours")), 0644)
+		reloads := make(chan Diff, 10)
+		scheduler := gocron.NewScheduler()
+		w, err := Watch(path, scheduler, ReloadOnSIGHUP(), OnReload(func(diff Diff, err error) {
+			if err != nil {
+				log("%v", err)
+			}
+			reloads <- diff
+		}))
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		defer w.Close()
+
+		reload := func() Diff {
+			select {
+			case diff := <-reloads:
+				return diff
+			case <-time.After(5 * time.Second):
+				return Diff{Unchanged: []string{"timeout"}}
+			}
+		}
+
+		// written in place
+		os.WriteFile(path, []byte(config("a schedule: every 2 hours")), 0644)
+		written := reload()
+		// replaced by renaming a new file over it
+		os.WriteFile(path+".new", []byte(config("a schedule: every 2 hours", "b schedule: every 1 hours")), 0644)
+		os.Rename(path+".new", path)
+		replaced := reload()
+		process, _ := os.FindProcess(os.Getpid())
+		process.Signal(syscall.SIGHUP)
+		hangup := reload()
+		log("%v; %v; %v %v", written, replaced, hangup, hangup.Unchanged)
+		return written.String() == "updated a" && replaced.String() == "added b" &&
+			reflect.DeepEqual(hangup.Unchanged, []string{"a", "b"}) && len(scheduler.Jobs()) == 2
+	})
+
+	s.Assert("`Watch(...)` returns the error of the config", func(log sugar.Log) bool {
+		path := filepath.Join(dir, "invalid.yaml")
+		os.WriteFile(path, []byte(config("a schedule: every 1 eons")), 0644)
+		_, err := Watch(path, gocron.NewScheduler())
+		return errors.Is(err, gocron.ErrSpecNotValid)
+	})
+
+	s.Assert("the watcher detects the swap of a symlink the file resolves through", func(log sugar.Log) bool {
+		// the layout of a Kubernetes ConfigMap volume, whose files are
+		// updated by swapping the `..data` link to a new directory
+		volume := filepath.Join(dir, "volume")
+		os.MkdirAll(filepath.Join(volume, "..v1"), 0755)
+		os.WriteFile(filepath.Join(volume, "..v1", "jobs.yaml"), []byte("jobs: []"), 0644)
+		os.Symlink("..v1", filepath.Join(volume, "..data"))
+		path := filepath.Join(volume, "jobs.yaml")
+		os.Symlink(filepath.Join("..data", "jobs.yaml"), path)
+
+		changes := make(chan struct{}, 1)
+		stop := make(chan struct{})
+		defer close(stop)
+		watchFile(path, 10*time.Millisecond, changes, stop)
+
+		// same size, and possibly the same modification time
+		os.MkdirAll(filepath.Join(volume, "..v2"), 0755)
+		os.WriteFile(filepath.Join(volume, "..v2", "jobs.yaml"), []byte("jobs: {}"), 0644)
+		os.Symlink("..v2", filepath.Join(volume, "..data_tmp"))
+		os.Rename(filepath.Join(volume, "..data_tmp"), filepath.Join(volume, "..data"))
+
+		select {
+		case <-changes:
+			return true
+		case <-time.After(time.Second):
+			log("the swap wasn't detected")
+			return false
+		}
+	})
+
+	s.Assert("the polling fallback detects the changes of the file", func(log sugar.Log) bool {
+		path := filepath.Join(dir, "polled.yaml")
+		changes := make(chan struct{}, 1)
+		stop := make(chan struct{})
+		defer close(stop)
+		pollFile(path, 10*time.Millisecond, changes, stop)
+
+		detected := func() bool {
+			select {
+			case <-changes:
+				return true
+			case <-time.After(time.Second):
+				return false
+			}
+		}
+		os.WriteFile(path, []byte("jobs: []"), 0644)
+		created := detected()
+		os.WriteFile(path, []byte("jobs: [] # changed"), 0644)
+		modified := detected()
+		os.Remove(path)
+		return created && modified && detected()
+	})
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/taka-wang/gocron"
+)
+
+// Diff is the report of the jobs changed by a reload, each list sorted by name
+type Diff struct {
+	// Added are the jobs new in the config
+	Added []string
+
+	// Removed are the jobs missing from the config, removed from the scheduler
+	Removed []string
+
+	// Updated are the changed jobs, updated in place with `gocron.Scheduler.UpdateDefinition`
+	Updated []string
+
+	// Unchanged are the jobs left as they are
+	Unchanged []string
+}
+
+// Changed returns true if jobs were added, removed or updated
+func (d Diff) Changed() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Updated) > 0
+}
+
+// String describes the changes, e.g. "added backup; removed report, cleanup"
+func (d Diff) String() string {
+	changes := []struct {
+		verb  string
+		names []string
+	}{{"added", d.Added}, {"removed", d.Removed}, {"updated", d.Updated}}
+
+	var parts []string
+	for _, change := range changes {
+		if len(change.names) > 0 {
+			parts = append(parts, change.verb+" "+strings.Join(change.names, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Reconcile changes the jobs the config was applied to a scheduler with into the jobs of `next`.
+// It adds the new jobs, removes the jobs missing from `next`, and updates the changed jobs in
+// place, so they keep their last run and their in-progress runs. The unchanged jobs are left as
+// they are, e.g. still paused by `PauseWithName`, instead of being reset like by `Clear`.
+// Like `Apply`, it validates every job of `next` first, and only changes the scheduler if all of
+// them are valid. A nil config adds every job of `next`
+//
+// Example
+//
+//  // ...
+//	next, err := config.Load("jobs.yaml")
+//	if err != nil {
+//		return err
+//	}
+//	diff, err := cfg.Reconcile(s, next)
+//	if err != nil {
+//		return err // the jobs of cfg are still scheduled
+//	}
+//	fmt.Println(diff) // e.g. "added backup; updated report"
+//	cfg = next
+//
+func (c *Config) Reconcile(s gocron.Scheduler, next *Config) (diff Diff, err error) {
+	defs, err := next.Definitions()
+	if err != nil {
+		return Diff{}, err
+	}
+
+	previous := make(map[string]gocron.JobDefinition)
+	if c != nil {
+		// the config was valid when it was applied
+		prevDefs, _ := c.Definitions()
+		for _, def := range prevDefs {
+			previous[def.Name] = def
+		}
+	}
+
+	defer diff.sort()
+	for _, def := range defs {
+		prev, ok := previous[def.Name]
+		delete(previous, def.Name)
+		switch {
+		case !ok:
+			if _, err := s.AddDefinition(def); err != nil {
+				return diff, err
+			}
+			diff.Added = append(diff.Added, def.Name)
+		case reflect.DeepEqual(prev, def):
+			diff.Unchanged = append(diff.Unchanged, def.Name)
+		default:
+			if _, err := s.UpdateDefinition(def); err != nil {
+				return diff, err
+			}
+			diff.Updated = append(diff.Updated, def.Name)
+		}
+	}
+	for name := range previous {
+		s.RemoveWithName(name)
+		diff.Removed = append(diff.Removed, name)
+	}
+	return diff, nil
+}
+
+// sort sorts the lists of the diff by name
+func (d *Diff) sort() {
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Updated)
+	sort.Strings(d.Unchanged)
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/taka-wang/gocron"
+)
+
+const (
+	// defaultPollInterval is the default interval of the polling of the config file
+	defaultPollInterval = time.Second
+
+	// settleDelay is how long the watcher waits for the writes to the config file to end
+	settleDelay = 100 * time.Millisecond
+)
+
+// Watcher applies a config file to a scheduler, and reconciles the scheduler with the file
+// when it changes, see `Config.Reconcile`, or optionally when the process receives SIGHUP.
+// The file is watched with inotify on Linux, and polled on the other systems
+type Watcher struct {
+	path      string
+	scheduler gocron.Scheduler
+
+	// interval of the polling of the file
+	interval time.Duration
+
+	// called with the diff or the error of each reload
+	onReload func(Diff, error)
+
+	// true if the file is reloaded when the process receives SIGHUP
+	hangup bool
+
+	// the config last applied to the scheduler
+	mutex  sync.Mutex
+	config *Config
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Option configures a watcher created by `Watch`
+type Option func(*Watcher)
+
+// PollInterval sets the interval the config file is polled at when it can't be watched
+// with inotify. The default interval is 1 second
+func PollInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.interval = d
+	}
+}
+
+// OnReload sets the function called with the diff of each reload, or the error of the
+// reload that failed, in which case the scheduler is unchanged
+func OnReload(fn func(Diff, error)) Option {
+	return func(w *Watcher) {
+		w.onReload = fn
+	}
+}
+
+// ReloadOnSIGHUP reloads the config file when the process receives SIGHUP, in addition to
+// when the file changes. By default the watcher leaves the signals to the application,
+// which can call `Watcher.Reload` itself
+func ReloadOnSIGHUP() Option {
+	return func(w *Watcher) {
+		w.hangup = true
+	}
+}
+
+// Watch applies the config file at `path` to the scheduler, and reloads it whenever the file
+// changes, see `ReloadOnSIGHUP` to reload it on SIGHUP too, until the watcher is closed.
+// It returns an error, and doesn't watch the file, if the config can't be loaded or applied
+//
+// Example
+//
+//  // ...
+//	w, err := config.Watch("jobs.yaml", s, config.ReloadOnSIGHUP(), config.OnReload(func(diff config.Diff, err error) {
+//		if err != nil {
+//			log.Printf("jobs.yaml: %v", err) // the previous jobs are still scheduled
+//			return
+//		}
+//		log.Printf("jobs.yaml: %v", diff) // e.g. "added backup; removed report"
+//	}))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer w.Close()
+//
+func Watch(path string, s gocron.Scheduler, options ...Option) (*Watcher, error) {
+	w := &Watcher{
+		path:      path,
+		scheduler: s,
+		interval:  defaultPollInterval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, option := range options {
+		option(w)
+	}
+
+	// watch before loading, so the changes made meanwhile aren't missed
+	changes := make(chan struct{}, 1)
+	watchFile(path, w.interval, changes, w.stop)
+	hangups := make(chan os.Signal, 1)
+	if w.hangup {
+		signal.Notify(hangups, syscall.SIGHUP)
+	}
+
+	if _, err := w.Reload(); err != nil {
+		signal.Stop(hangups)
+		close(w.stop)
+		return nil, err
+	}
+	go w.run(changes, hangups)
+	return w, nil
+}
+
+// Reload loads the config file, and reconciles the scheduler with it. It returns the
+// diff of the jobs, or an error in which case the scheduler is unchanged
+func (w *Watcher) Reload() (Diff, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	next, err := Load(w.path)
+	if err != nil {
+		return Diff{}, err
+	}
+	diff, err := w.config.Reconcile(w.scheduler, next)
+	if err != nil {
+		return diff, err
+	}
+	w.config = next
+	return diff, nil
+}
+
+// Close stops watching the config file. The jobs stay in the scheduler
+func (w *Watcher) Close() error {
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	<-w.done
+	return nil
+}
+
+// run reloads the config file on its changes and on SIGHUP, if enabled, until the watcher is closed
+func (w *Watcher) run(changes <-chan struct{}, hangups chan os.Signal) {
+	defer close(w.done)
+	defer signal.Stop(hangups)
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-hangups:
+		case <-changes:
+			// wait for the writes to end, coalescing their changes
+			select {
+			case <-time.After(settleDelay):
+			case <-w.stop:
+				return
+			}
+			select {
+			case <-changes:
+			default:
+			}
+		}
+
+		diff, err := w.Reload()
+		if w.onReload != nil {
+			w.onReload(diff, err)
+		}
+	}
+}
+
+// pollFile checks the file every `interval` until `stop` is closed, and signals
+// on `changes` when it changed, see `fileChanged`
+func pollFile(path string, interval time.Duration, changes chan<- struct{}, stop <-chan struct{}) {
+	last, _ := os.Stat(path)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			info, _ := os.Stat(path)
+			if fileChanged(last, info) {
+				last = info
+				notify(changes)
+			}
+		}
+	}()
+}
+
+// fileChanged returns true if the file was created, deleted, modified or replaced
+// between the two infos. The info of a missing file is nil
+func fileChanged(last, info os.FileInfo) bool {
+	if last == nil || info == nil {
+		return (last == nil) != (info == nil)
+	}
+	return last.Size() != info.Size() || !last.ModTime().Equal(info.ModTime()) || !os.SameFile(last, info)
+}
+
+// notify sends to the channel unless a change is already pending
+func notify(changes chan<- struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// inotifyMask are the events of the directory of the config file that may change it, including
+// the editors and the tools replacing the file by renaming a new one over it, and the swap of
+// a symlink the file resolves through, like the `..data` link of a Kubernetes ConfigMap volume
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM |
+	syscall.IN_CREATE | syscall.IN_DELETE
+
+// watchFile watches the directory of the file with inotify until `stop` is closed, and
+// signals on `changes` when the file is written, or when an event of the directory replaced
+// the file it resolves to. It polls the file every `interval` if inotify isn't available,
+// e.g. when the limit of watches is reached
+func watchFile(path string, interval time.Duration, changes chan<- struct{}, stop <-chan struct{}) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		pollFile(path, interval, changes, stop)
+		return
+	}
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Dir(path), inotifyMask); err != nil {
+		syscall.Close(fd)
+		pollFile(path, interval, changes, stop)
+		return
+	}
+	// the non blocking file is read through the runtime poller, so closing it ends the read
+	file := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		<-stop
+		file.Close()
+	}()
+
+	last, _ := os.Stat(path)
+	go func() {
+		name := filepath.Base(path)
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := file.Read(buf)
+			if err != nil {
+				return
+			}
+			info, _ := os.Stat(path)
+			if hasEvent(buf[:n], name) || fileChanged(last, info) {
+				last = info
+				notify(changes)
+			}
+		}
+	}()
+}
+
+// hasEvent returns true if the inotify events are about the file `name`, or
+// if events were dropped in which case the file may have changed
+func hasEvent(buf []byte, name string) bool {
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		offset += syscall.SizeofInotifyEvent
+		end := offset + int(event.Len)
+		if end > len(buf) {
+			end = len(buf)
+		}
+		if event.Mask&syscall.IN_Q_OVERFLOW != 0 || strings.TrimRight(string(buf[offset:end]), "\x00") == name {
+			return true
+		}
+		offset = end
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package config
+
+import "time"
+
+// watchFile polls the file every `interval` until `stop` is closed, and
+// signals on `changes` when it changes
+func watchFile(path string, interval time.Duration, changes chan<- struct{}, stop <-chan struct{}) {
+	pollFile(path, interval, changes, stop)
+}
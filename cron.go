@@ -0,0 +1,268 @@
+package gocron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros maps the predefined `@` schedules onto their six-field expression
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cronField describes the valid values of a single cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField = cronField{"second", 0, 59, nil}
+	minuteField = cronField{"minute", 0, 59, nil}
+	hourField   = cronField{"hour", 0, 23, nil}
+	domField    = cronField{"day of month", 1, 31, nil}
+	monthField  = cronField{"month", 1, 12, monthNames}
+	dowField    = cronField{"day of week", 0, 7, weekdayNames}
+)
+
+// cronSchedule is a parsed cron expression. Every field is stored as a bit set
+// in which bit `n` is set when the value `n` matches.
+type cronSchedule struct {
+	expr string
+
+	second, minute, hour, dom, month, dow uint64
+
+	// wildcard day fields, used to apply the cron rule that a job runs when
+	// either the day of month or the day of week matches if both are restricted
+	domStar, dowStar bool
+}
+
+// parseCron parses a standard five-field (minute hour dom month dow) or
+// six-field (second minute hour dom month dow) cron expression, or one of the
+// `@yearly`, `@annually`, `@monthly`, `@weekly`, `@daily`, `@midnight` and `@hourly` macros
+func parseCron(expr string) (*cronSchedule, error) {
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		macro, ok := cronMacros[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown macro %q", ErrCronExpressionNotValid, spec)
+		}
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: expected 5 or 6 fields, found %d in %q", ErrCronExpressionNotValid, len(fields), expr)
+	}
+
+	c := &cronSchedule{expr: expr}
+	var err error
+	if c.second, _, err = secondField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.minute, _, err = minuteField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.hour, _, err = hourField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.dom, c.domStar, err = domField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if c.month, _, err = monthField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if c.dow, c.dowStar, err = dowField.parse(fields[5]); err != nil {
+		return nil, err
+	}
+	// 7 is an alias for sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+
+	// reject expressions such as `0 0 30 2 *` that can never be satisfied.
+	// The reference year is a leap year so that February 29th is reachable
+	if c.Next(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)).IsZero() {
+		return nil, fmt.Errorf("%w: %q never matches", ErrCronExpressionNotValid, expr)
+	}
+
+	return c, nil
+}
+
+// parse converts a field such as `*/5`, `1-10/2`, `MON-FRI` or `1,15,30` into a bit set.
+// It also reports whether the field was a wildcard
+func (f cronField) parse(field string) (bits uint64, star bool, err error) {
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step := f.min, f.max, 1
+
+		rng := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, false, f.errorf("invalid step in %q", part)
+			}
+		}
+
+		switch {
+		case rng == "*" || rng == "?":
+			star = star || step == 1
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, false, err
+			}
+			if hi, err = f.value(bounds[1]); err != nil {
+				return 0, false, err
+			}
+			if lo > hi {
+				return 0, false, f.errorf("range %q is reversed", rng)
+			}
+		default:
+			if lo, err = f.value(rng); err != nil {
+				return 0, false, err
+			}
+			// `n/step` is shorthand for `n-max/step`
+			if step == 1 {
+				hi = lo
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+// value converts a single number or name into its value within the field bounds
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, f.errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, f.errorf("value %d out of range [%d, %d]", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (f cronField) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s: %s", ErrCronExpressionNotValid, f.name, fmt.Sprintf(format, args...))
+}
+
+// Next returns the first time after `after` that matches the expression,
+// evaluated in the location of `after`. It returns the zero time if nothing
+// matches within the next five years, so the job never runs again
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+
+	// start at the next whole second
+	t := after.Add(time.Second - time.Duration(after.Nanosecond()))
+	added := false
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for c.month&(1<<uint(t.Month())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !c.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		// midnight may not exist on days a daylight saving transition happens
+		if t.Hour() != 0 {
+			if t.Hour() > 12 {
+				t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+			} else {
+				t = t.Add(-time.Duration(t.Hour()) * time.Hour)
+			}
+		}
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for c.hour&(1<<uint(t.Hour())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for c.minute&(1<<uint(t.Minute())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for c.second&(1<<uint(t.Second())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t
+}
+
+// dayMatches returns true if the day of `t` satisfies the day of month and day of week fields
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
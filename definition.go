@@ -0,0 +1,223 @@
+package gocron
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TaskDefinition is a registered task of a job and its arguments, see `DoTask`
+type TaskDefinition struct {
+	// Name is the name the task is registered under
+	Name string `json:"name"`
+
+	// Args are the JSON arguments of the task
+	Args []json.RawMessage `json:"args,omitempty"`
+}
+
+// JobDefinition is a JSON serializable definition of a job running registered tasks.
+// It round-trips through `Job.Definition` and `Scheduler.AddDefinition`
+//
+// Example
+//
+//  // ...
+//	def, err := job.Definition()
+//	data, err := json.Marshal(def)
+//	// ... later, or in another process registering the same tasks
+//	json.Unmarshal(data, &def)
+//	job, err := s.AddDefinition(def)
+//
+type JobDefinition struct {
+	// Name is the name of the job, see `EveryWithName`
+	Name string `json:"name"`
+
+	// Schedule is the schedule spec of the job, see `Job.Spec`
+	Schedule string `json:"schedule"`
+
+	// Location is the name of the location of the job, e.g. "Asia/Taipei".
+	// The default location of the scheduler is used if it is empty
+	Location string `json:"location,omitempty"`
+
+	// Tasks are the registered tasks of the job
+	Tasks []TaskDefinition `json:"tasks"`
+
+	// Paused is true if the job is paused
+	Paused bool `json:"paused,omitempty"`
+
+	// Timeout is the maximum duration of a run, see `Job.Timeout`
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Tags are the labels of the job, see `Job.Tag`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Definition returns the definition of the job. It returns an error if the job has
+// a custom `Schedule`, or tasks passed to `Do` instead of `DoTask`
+func (j *Job) Definition() (JobDefinition, error) {
+	defer j.lock()()
+
+	return j.definition()
+}
+
+// definition returns the definition of the job. The mutex of its scheduler must be held
+func (j *Job) definition() (JobDefinition, error) {
+	def := JobDefinition{
+		Name:     j.name,
+		Schedule: j.spec(),
+		Location: j.location.String(),
+		Paused:   !j.enabled,
+		Tags:     append([]string(nil), j.tags...),
+	}
+	if def.Schedule == "custom" {
+		return def, fmt.Errorf("%w: job %q has a custom schedule", ErrSpecNotValid, j.name)
+	}
+
+	j.state.mutex.Lock()
+	def.Timeout = j.state.timeout
+	def.Tasks = append([]TaskDefinition(nil), j.tasksDef...)
+	j.state.mutex.Unlock()
+
+	for _, task := range def.Tasks {
+		if task.Name == "" {
+			return def, fmt.Errorf("%w: job %q has tasks passed to `Do`", ErrTaskNotRegistered, j.name)
+		}
+	}
+	return def, nil
+}
+
+// job builds the job of the definition, which isn't added to a scheduler yet.
+// The job is in `location` unless the definition has a location.
+// It returns the first error recorded while building the job
+func (def JobDefinition) job(location *time.Location) (*Job, error) {
+	job := newJob(1)
+	job.setName(def.Name)
+	if def.Location != "" {
+		loc, err := time.LoadLocation(def.Location)
+		if err != nil {
+			job.setError("Location", def.Location, err)
+			return job, job.err
+		}
+		location = loc
+	}
+	job.Location(location).Spec(def.Schedule).Timeout(def.Timeout).Tag(def.Tags...)
+	for _, task := range def.Tasks {
+		job.doTask(task)
+	}
+	if len(def.Tasks) == 0 {
+		job.setError("Tasks", def.Tasks, fmt.Errorf("%w: the job has no task", ErrTaskNotRegistered))
+	}
+	job.enabled = !def.Paused
+	return job, job.err
+}
+
+// Validate returns the first error of the job of the definition, or nil if it is valid
+func (def JobDefinition) Validate() error {
+	_, err := def.job(time.Local)
+	return err
+}
+
+// AddDefinition creates a job from its definition, and adds it to the scheduler. Like
+// `EveryWithName`, it replaces the job with the same name. It returns an error, and
+// doesn't add the job, if the definition isn't valid, e.g. its tasks aren't registered
+func (s *scheduler) AddDefinition(def JobDefinition) (*Job, error) {
+	defer s.notify()
+	s.mutex.Lock()
+	location := s.location
+	s.mutex.Unlock()
+
+	job, err := def.job(location)
+	if err != nil {
+		return nil, err
+	}
+
+	// load the state before locking, since the store may be slow
+	restored, err := s.restore(def.Name)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.addNamed(job, restored, err)
+	return job, nil
+}
+
+// UpdateDefinition changes the job with the same name as the definition in place. The job keeps
+// its last run, its in-progress runs and its overlap, retry and misfire policies, and its next run
+// follows the new schedule from its last run. It adds the job like `AddDefinition` if there is no
+// job with the name. It returns an error, and doesn't change the job, if the definition isn't valid
+func (s *scheduler) UpdateDefinition(def JobDefinition) (*Job, error) {
+	s.mutex.Lock()
+	location := s.location
+	_, ok := s.jobMap[def.Name]
+	s.mutex.Unlock()
+	if !ok {
+		return s.AddDefinition(def)
+	}
+
+	next, err := def.job(location)
+	if err != nil {
+		return nil, err
+	}
+
+	defer s.notify()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobMap[def.Name]
+	if !ok {
+		// the job was removed in the meantime
+		s.addNamed(next, nil, nil)
+		return next, nil
+	}
+	paused := !job.enabled
+	job.redefine(next)
+	switch {
+	case job.isInit():
+	case s.isRunning:
+		job.init(s.clock.Now())
+	default:
+		// the job is initialized by the next run of the scheduler
+		job.nextRun = time.Time{}
+	}
+	if s.jobs.contains(job) {
+		s.jobs.update(job)
+	}
+	s.rearm()
+	s.persist(job)
+	if paused != def.Paused {
+		if def.Paused {
+			s.emit(func(l Listener) { l.JobPaused(job) })
+		} else {
+			s.emit(func(l Listener) { l.JobResumed(job) })
+		}
+	}
+	return job, nil
+}
+
+// redefine replaces the schedule, the tasks, the timeout, the tags and the
+// enabled flag of the job with the ones of `next`, built from a definition.
+// The next run of an initialized job is computed again from its last run
+func (j *Job) redefine(next *Job) {
+	j.interval = next.interval
+	j.unit = next.unit
+	j.atTimes = next.atTimes
+	j.weekDays = next.weekDays
+	j.monthDay = next.monthDay
+	j.nthWeekday = next.nthWeekday
+	j.schedule = next.schedule
+	j.location = next.location
+	j.tags = next.tags
+	j.err = nil
+
+	j.state.mutex.Lock()
+	j.tasks = next.tasks
+	j.tasksParams = next.tasksParams
+	j.tasksContext = next.tasksContext
+	j.tasksDef = next.tasksDef
+	j.enabled = next.enabled
+	j.state.timeout = next.state.timeout
+	j.state.mutex.Unlock()
+
+	if j.isInit() {
+		// the defaults of the new schedule are derived from the last run
+		j.init(j.lastRun)
+	}
+}
@@ -1,20 +1,117 @@
 package gocron
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	// ErrTaskIsNotAFuncError is the error panicked when a task passed to `Job.Do`
+	// ErrTaskIsNotAFuncError is the error recorded when a task passed to `Job.Do` is not a func
 	ErrTaskIsNotAFuncError = errors.New("the `task` your a scheduling must be of type func")
 
-	// ErrMissmatchedTaskParams is the error panicked when someone passes too many or too few params to `Job.Do`
-	ErrMissmatchedTaskParams = errors.New("the `task` your a scheduling must be of type func")
+	// ErrMissmatchedTaskParams is the error recorded when someone passes too many or too few params to `Job.Do`
+	ErrMissmatchedTaskParams = errors.New("the `task` your a scheduling must be passed as many params as it takes")
 
 	// ErrJobIsNotInitialized is the error panicked when a job is scheduled that was not initialized
 	ErrJobIsNotInitialized = errors.New("this job was not intialized")
 
-	// ErrIncorrectTimeFormat is the error panicked when `At` is passed an incorrect time
+	// ErrIncorrectTimeFormat is the error recorded when `At` is passed an incorrect time
 	ErrIncorrectTimeFormat = errors.New("the time format is incorrect")
 
-	// ErrIntervalNotValid error panicked when the interval is not valid
-	ErrIntervalNotValid = errors.New("the interval must be greater than 0")
+	// ErrIntervalNotValid error recorded when the interval is not valid, i.e. 0 or without a unit
+	ErrIntervalNotValid = errors.New("the interval must be greater than 0 and have a unit")
+
+	// ErrDayOfMonthNotValid is the error recorded when `DayOfMonth` is passed a day outside of a month
+	ErrDayOfMonthNotValid = errors.New("the day of month must be between -30 and 31, and not 0")
+
+	// ErrNthWeekdayNotValid is the error recorded when `NthWeekday` is passed an occurrence outside of a month
+	ErrNthWeekdayNotValid = errors.New("the nth weekday must be between -4 and 4, and not 0")
+
+	// ErrCronExpressionNotValid is the error recorded when `Cron` is passed an invalid cron expression
+	ErrCronExpressionNotValid = errors.New("the cron expression is not valid")
+
+	// ErrTaskPanicked is matched by the `*PanicError` of a run whose task panicked
+	ErrTaskPanicked = errors.New("the task panicked")
+
+	// ErrSpecNotValid is the error recorded when `Spec` is passed an invalid schedule spec
+	ErrSpecNotValid = errors.New("the schedule spec is not valid")
+
+	// ErrTaskNotRegistered is the error recorded when `DoTask` is passed the name of a task
+	// that wasn't registered by `RegisterTask`, and returned by `Definition` for the tasks passed to `Do`
+	ErrTaskNotRegistered = errors.New("the task is not registered")
+
+	// ErrTaskAlreadyRegistered is the error returned when `RegisterTask` is passed the name of a registered task
+	ErrTaskAlreadyRegistered = errors.New("a task is already registered with this name")
+
+	// ErrJobNotFound is the error returned when `UpdateIntervalWithName` is passed the name of no job
+	ErrJobNotFound = errors.New("no job has this name")
+
+	// ErrScheduleHasNoInterval is the error returned when `UpdateIntervalWithName` is passed
+	// the name of a job whose schedule has no interval, e.g. a cron expression or a custom `Schedule`
+	ErrScheduleHasNoInterval = errors.New("the schedule of the job has no interval")
 )
+
+// JobError is the error recorded by a `Job` when it is built with an invalid argument.
+// It wraps one of the errors above, so callers can match it with `errors.Is`
+//
+// Example
+//
+//  // ...
+//  job := s.EveryWithName(1, "report").Day().At(config.ReportTime).Do(task)
+//  if errors.Is(job.Err(), gocron.ErrIncorrectTimeFormat) {
+//  	// ...
+//  }
+//
+type JobError struct {
+	// Job is the name of the job, empty for jobs created with `Every`
+	Job string
+
+	// Op is the method that was passed the invalid argument, e.g. "At"
+	Op string
+
+	// Arg is the invalid argument
+	Arg interface{}
+
+	// Err is the reason the argument is invalid
+	Err error
+}
+
+// Error returns the job, method, argument and reason of the error
+func (e *JobError) Error() string {
+	job := "job"
+	if e.Job != "" {
+		job = fmt.Sprintf("job %q", e.Job)
+	}
+	return fmt.Sprintf("gocron: %s: %s(%#v): %v", job, e.Op, e.Arg, e.Err)
+}
+
+// Unwrap returns the reason of the error
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is the error of a run whose task panicked. It matches `ErrTaskPanicked`
+// with `errors.Is`, and unwraps to the panic value if it is an error
+type PanicError struct {
+	// Value is the value the task panicked with
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine at the time of the panic
+	Stack []byte
+}
+
+// Error returns the panic value and the stack trace
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("gocron: %v: %v\n%s", ErrTaskPanicked, e.Value, e.Stack)
+}
+
+// Is returns true if `target` is `ErrTaskPanicked`
+func (e *PanicError) Is(target error) bool {
+	return target == ErrTaskPanicked
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
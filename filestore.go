@@ -0,0 +1,174 @@
+package gocron
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// FileStore is a `JobStore` keeping the state of the jobs in a JSON file.
+// The file is replaced atomically on each write, so it is never left half written.
+// The states saved by the scheduler at the same time, e.g. by the jobs due at the
+// same time, are written at once
+//
+// Example
+//
+//  // ...
+//	store, err := NewFileStore("/var/lib/myservice/jobs.json")
+//	if err != nil {
+//		return err
+//	}
+//	s := NewScheduler(WithStore(store))
+//	s.EveryWithName(1, "backup").Day().At("02:00").Do(backup) // resumes where it left off before a restart
+//
+type FileStore struct {
+	path string
+
+	mutex sync.Mutex
+	jobs  map[string]JobState
+
+	// true while the writes are batched, and the file is written by `commit`
+	batching bool
+	dirty    bool
+}
+
+// NewFileStore opens the store kept in the file at `path`, which is
+// created on the first write if it doesn't exist
+func NewFileStore(path string) (*FileStore, error) {
+	f := &FileStore{path: path, jobs: make(map[string]JobState)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var states []JobState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		f.jobs[state.Name] = state
+	}
+	return f, nil
+}
+
+// Load returns the state of the job
+func (f *FileStore) Load(name string) (JobState, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	state, ok := f.jobs[name]
+	return state, ok, nil
+}
+
+// Save saves the state of the job, and writes the file
+func (f *FileStore) Save(state JobState) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.jobs[state.Name] = state
+	return f.changed()
+}
+
+// Delete deletes the state of the job, and writes the file
+func (f *FileStore) Delete(name string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, ok := f.jobs[name]; !ok {
+		return nil
+	}
+	delete(f.jobs, name)
+	return f.changed()
+}
+
+// begin batches the writes of the file until `commit` is called
+func (f *FileStore) begin() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.batching = true
+}
+
+// commit writes the file once for the writes batched since `begin`
+func (f *FileStore) commit() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.batching = false
+	if !f.dirty {
+		return nil
+	}
+	f.dirty = false
+	return f.write()
+}
+
+// changed writes the file, or records that it must be written when the writes are batched.
+// The mutex must be held
+func (f *FileStore) changed() error {
+	if f.batching {
+		f.dirty = true
+		return nil
+	}
+	return f.write()
+}
+
+// write writes the states of the jobs sorted by name to a temporary file in the directory
+// of the store, renames it to the file of the store, and syncs the directory so the rename
+// survives a crash. The mutex must be held
+func (f *FileStore) write() error {
+	states := make([]JobState, 0, len(f.jobs))
+	for _, state := range f.jobs {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, k int) bool { return states[i].Name < states[k].Name })
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	// the temporary file is gone once it is renamed
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(f.path))
+}
+
+// syncDir flushes the entries of the directory at `path` to disk
+func syncDir(path string) error {
+	// directories can't be synced on Windows, where the rename is durable already
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+	return dir.Close()
+}
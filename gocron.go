@@ -21,6 +21,7 @@
 package gocron
 
 import (
+	"context"
 	"time"
 )
 
@@ -36,6 +37,26 @@ func EveryWithName(interval uint64, name string) *Job {
 	return defaultScheduler.EveryWithName(interval, name)
 }
 
+// Cron schedules a new job from a cron expression in the default scheduler
+func Cron(expr string) *Job {
+	return defaultScheduler.Cron(expr)
+}
+
+// AddDefinition creates a job from its definition in the default scheduler
+func AddDefinition(def JobDefinition) (*Job, error) {
+	return defaultScheduler.AddDefinition(def)
+}
+
+// UpdateDefinition changes the job with the same name as the definition in place in the default scheduler
+func UpdateDefinition(def JobDefinition) (*Job, error) {
+	return defaultScheduler.UpdateDefinition(def)
+}
+
+// AddListener registers a listener of the default scheduler
+func AddListener(listener Listener) {
+	defaultScheduler.AddListener(listener)
+}
+
 // Emergency schedules a new emergency job in the default scheduler
 func Emergency() *Job {
 	return defaultScheduler.Emergency()
@@ -43,10 +64,10 @@ func Emergency() *Job {
 
 // RunPending Runs all of the jobs that are scheduled to run
 //
-// Please note that it is *intended behavior that `RunPending()`
-// does not run missed jobs*. For example, if you've registered a job
-// that should run every minute and you only call `RunPending()`
-// in one hour increments then your job will only be run once every hour
+// Please note that by default `RunPending()` runs missed jobs only once.
+// For example, if you've registered a job that should run every minute
+// and you only call `RunPending()` in one hour increments then your job
+// will only be run once every hour. See `Job.Misfire` to change this
 func RunPending() {
 	defaultScheduler.RunPending()
 }
@@ -81,6 +102,11 @@ func Stop() {
 	defaultScheduler.Stop()
 }
 
+// Shutdown stops the default scheduler, and waits for its in-progress runs to finish until `ctx` is done
+func Shutdown(ctx context.Context) ([]*Job, error) {
+	return defaultScheduler.Shutdown(ctx)
+}
+
 // Clear removes all of the jobs from the default scheduler
 func Clear() {
 	defaultScheduler.Clear()
@@ -110,3 +136,8 @@ func ResumeWithName(name string) {
 func NextRun() (job *Job, time time.Time) {
 	return defaultScheduler.NextRun()
 }
+
+// Jobs returns the jobs of the default scheduler
+func Jobs() []*Job {
+	return defaultScheduler.Jobs()
+}
@@ -2,11 +2,23 @@
 package gocron
 
 import (
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/takawang/sugar"
+	"github.com/taka-wang/gocron/internal/sugar"
 )
 
 func task() {
@@ -26,483 +38,2627 @@ func taskWithParams(a int, b string) {
 	fmt.Println(a, b, t.Format("2006-01-02 15:04:05.000"))
 }
 
-/*
 func TestJob(t *testing.T) {
 
-	// note: we're defining today as the first of the month so we can test an important edge case in the lastRun
-	// calculation when a jobs lastRun time occured the previous month from when the job initialized
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), 1, now.Hour(), now.Minute(), now.Second(), 0, now.Location())
+	s := sugar.New(t)
+
+	// Wednesday, 2016-01-06 10:20:30
+	today := time.Date(2016, time.January, 6, 10, 20, 30, 0, time.Local)
 	aMinuteAgo := today.Add(-time.Minute)
 	aMinuteFromNow := today.Add(time.Minute)
-	aMinuteAgoAtTime := fmt.Sprintf("%02d:%02d", aMinuteAgo.Hour(), aMinuteAgo.Minute())
-	aMinuteFromNowAtTime := fmt.Sprintf("%02d:%02d", aMinuteFromNow.Hour(), aMinuteFromNow.Minute())
-	s := sugar.New(t)
+	aMinuteAgoAtTime := aMinuteAgo.Format("15:04:05")
+	aMinuteFromNowAtTime := aMinuteFromNow.Format("15:04:05")
 
 	s.Title("Day")
 
 	s.Assert("`Job.Every(...).Day().At(...)`", func(log sugar.Log) bool {
-		// try this with 20 random day intervals
-		for i := 20; i > 0; i-- {
-
-			// get a random interval of days [1, 5]
-			rand.Seed(time.Now().UnixNano())
-			interval := 1 + uint64(rand.Int())%6
-
+		for interval := uint64(1); interval <= 5; interval++ {
 			// create and init the job
 			job := newJob(interval).Day().At(aMinuteFromNowAtTime)
 			job.init(today)
 
-			// jobs last run should be `interval` days from now
-			aMinuteFromNowIntervalDaysFromnow := aMinuteFromNow
-			if !job.lastRun.Equal(aMinuteFromNow) {
-				log("the lastRun did not occur a minute from now days ago")
-				log(job.lastRun, aMinuteFromNowIntervalDaysFromnow)
-				return false
-			}
-			//
-			// // jobs last run should be `interval` days from now
-			// aMinuteAgoIntervalDaysAgo := aMinuteAgo.Add(-1 * Day * time.Duration(interval))
-			// if !job.lastRun.Equal(aMinuteAgoIntervalDaysAgo) {
-			// 	log("the lastRun did not occur %d days ago", interval)
-			// 	log(job.lastRun, aMinuteFromNowIntervalDaysAgo)
-			// 	return false
-			// }
-
 			// jobs next run is should be today
-			if !job.nextRun.Equal(aMinuteFromNow) {
+			if !job.lastRun.Equal(today) || !job.nextRun.Equal(aMinuteFromNow) {
 				log("the nextRun will not happen a minute from now")
-				log(job.nextRun, aMinuteFromNow)
+				log("%v %v", job.lastRun, job.nextRun)
 				return false
 			}
 
 			// after run, the nextRun is interval days from the previous nextRun
 			job.run()
-			aMinutFromNowIntervalDaysAfterNextRun := aMinuteFromNow.Add(Day * time.Duration(interval))
-			if !job.nextRun.Equal(aMinutFromNowIntervalDaysAfterNextRun) {
+			if expected := aMinuteFromNow.AddDate(0, 0, int(interval)); !job.nextRun.Equal(expected) {
 				log("the next nextRun will not happen in %d days", interval)
-				log(job.nextRun, aMinutFromNowIntervalDaysAfterNextRun)
+				log("%v %v", job.nextRun, expected)
 				return false
 			}
-
 		}
-
 		return true
 	})
 
 	s.Assert("`Job.Every(...).Day.At(...)` set to the past", func(log sugar.Log) bool {
-		// try this with 20 random day intervals
-		for i := 20; i > 0; i-- {
-
-			// get a random interval of days [1, 5]
-			rand.Seed(time.Now().UnixNano())
-			interval := 1 + uint64(rand.Int())%5
-
+		for interval := uint64(1); interval <= 5; interval++ {
 			// create and init the job
 			job := newJob(interval).Day().At(aMinuteAgoAtTime)
 			job.init(today)
 
-			// jobs last run interval days from tomorrow
-			aMinuteAgoIntervalDaysFromTomorrow := aMinuteAgo.Add(Day).Add(-1 * Day * time.Duration(interval))
-			if !job.lastRun.Equal(aMinuteAgoIntervalDaysFromTomorrow) {
-				log("the lastRun did not %d days from tomorrow", interval)
-				log(job.lastRun, aMinuteAgoIntervalDaysFromTomorrow)
-				return false
-			}
-
-			// jobs next run is tomorrow
-			aMinuteAgoTomorrow := aMinuteAgo.Add(Day)
-			if !job.nextRun.Equal(aMinuteAgoTomorrow) {
-				log("the nextRun will not occur tomorrow")
-				log(job.nextRun, aMinuteAgoTomorrow)
+			// jobs next run is `interval` days from today
+			aMinuteAgoIntervalDaysFromToday := aMinuteAgo.AddDate(0, 0, int(interval))
+			if !job.nextRun.Equal(aMinuteAgoIntervalDaysFromToday) {
+				log("the nextRun will not occur in %d days", interval)
+				log("%v %v", job.nextRun, aMinuteAgoIntervalDaysFromToday)
 				return false
 			}
 
 			// after run, the nextRun is interval days from the previous nextRun
 			job.run()
-			aMinutAgoIntervalDaysAfterNextRun := aMinuteAgoTomorrow.Add(Day * time.Duration(interval))
-			if !job.nextRun.Equal(aMinutAgoIntervalDaysAfterNextRun) {
+			if expected := aMinuteAgoIntervalDaysFromToday.AddDate(0, 0, int(interval)); !job.nextRun.Equal(expected) {
 				log("the next nextRun will not happen in %d days", interval)
-				log(job.nextRun, aMinutAgoIntervalDaysAfterNextRun)
+				log("%v %v", job.nextRun, expected)
 				return false
 			}
 		}
-
 		return true
 	})
 
 	s.Title("Week")
 
 	s.Assert("`Job.Every(...).Weekday(...).At(...)` set to the past", func(log sugar.Log) bool {
-		// try this with 20 random weekdays and week intervals
-		for i := 20; i > 0; i-- {
-
-			// get a random interval of weeks [1, 52]
-			rand.Seed(time.Now().UnixNano())
-			interval := 1 + uint64(rand.Int())%52
-
-			// get a random day of the week that is today or before today
-			rand.Seed(time.Now().UnixNano())
-			weekday := time.Weekday(rand.Int() % int(today.Weekday()+1))
-			durationAfterWeekday := time.Duration(weekday-today.Weekday()) * 24 * time.Hour
-
-			// create and init the job
-			job := newJob(interval).Weekday(weekday).At(aMinuteAgoAtTime)
-			job.init(today)
-
-			// jobs lastRun was interval weeks ago from next week
-			aMinuteAgoIntervalWeeksFromNextWeek := aMinuteAgo.Add(durationAfterWeekday).Add(Week).Add(-1 * Week * time.Duration(interval))
-			if !job.lastRun.Equal(aMinuteAgoIntervalWeeksFromNextWeek) {
-				log("the lastRun did not occur %d weeks ago", interval+1)
-				log(weekday, aMinuteAgoIntervalWeeksFromNextWeek.Weekday(), job.nextRun.Weekday(), job.lastRun, aMinuteAgoIntervalWeeksFromNextWeek)
-				return false
+		for interval := uint64(1); interval <= 3; interval++ {
+			for weekday := time.Sunday; weekday <= today.Weekday(); weekday++ {
+				// create and init the job
+				job := newJob(interval).Weekday(weekday).At(aMinuteAgoAtTime)
+				job.init(today)
+
+				// jobs next run is on the weekday `interval` weeks from this week
+				aMinuteAgoIntervalWeeksFromThisWeek := aMinuteAgo.AddDate(0, 0, int(weekday-today.Weekday())+7*int(interval))
+				if !job.nextRun.Equal(aMinuteAgoIntervalWeeksFromThisWeek) {
+					log("the nextRun on %v will not occur in %d weeks", weekday, interval)
+					log("%v %v", job.nextRun, aMinuteAgoIntervalWeeksFromThisWeek)
+					return false
+				}
+
+				// after run, the nextRun is interval weeks from the previous nextRun
+				job.run()
+				if expected := aMinuteAgoIntervalWeeksFromThisWeek.AddDate(0, 0, 7*int(interval)); !job.nextRun.Equal(expected) {
+					log("the next nextRun will not happen in %d weeks", interval)
+					log("%v %v", job.nextRun, expected)
+					return false
+				}
 			}
-
-			// jobs next run is next week
-			aMinuteAgoNextWeek := aMinuteAgo.Add(durationAfterWeekday).Add(Week)
-			if !job.nextRun.Equal(aMinuteAgoNextWeek) {
-				log("the nextRun will not occur next week")
-				log(weekday, aMinuteAgoNextWeek.Weekday(), job.nextRun.Weekday(), job.nextRun, aMinuteAgoNextWeek)
-				return false
-			}
-
-			// after run, the nextRun is interval weeks from the previous nextRun
-			job.run()
-			aMinutAgoIntervalWeeksAfterNextRun := aMinuteAgoNextWeek.Add(Week * time.Duration(interval))
-			if !job.nextRun.Equal(aMinutAgoIntervalWeeksAfterNextRun) {
-				log("the next nextRun will not happen in %d weeks", interval)
-				log(job.nextRun, aMinutAgoIntervalWeeksAfterNextRun)
-				return false
-			}
-
 		}
-
 		return true
 	})
 
 	s.Assert("`Job.Every(...).Weekday(...).At(...)` set to the future", func(log sugar.Log) bool {
-		// try this with 20 random weekdays and week intervals
-		for i := 20; i > 0; i-- {
-
-			// get a random interval of weeks [1, 52]
-			rand.Seed(time.Now().UnixNano())
-			interval := 1 + uint64(rand.Int())%52
+		for interval := uint64(1); interval <= 3; interval++ {
+			for weekday := today.Weekday(); weekday <= time.Saturday; weekday++ {
+				// create and init the job
+				job := newJob(interval).Weekday(weekday).At(aMinuteFromNowAtTime)
+				job.init(today)
+
+				// jobs next run is this week
+				thisWeekdayAMinuteFromNow := aMinuteFromNow.AddDate(0, 0, int(weekday-today.Weekday()))
+				if !job.nextRun.Equal(thisWeekdayAMinuteFromNow) {
+					log("the nextRun on %v will not occur this week", weekday)
+					log("%v %v", job.nextRun, thisWeekdayAMinuteFromNow)
+					return false
+				}
+
+				// after run, the nextRun is interval weeks from the previous nextRun
+				job.run()
+				if expected := thisWeekdayAMinuteFromNow.AddDate(0, 0, 7*int(interval)); !job.nextRun.Equal(expected) {
+					log("the next nextRun will not happen in %d weeks", interval)
+					log("%v %v", job.nextRun, expected)
+					return false
+				}
+			}
+		}
+		return true
+	})
 
-			// get a random day of the week that is today or after today
-			rand.Seed(time.Now().UnixNano())
-			weekday := time.Weekday(int(today.Weekday()) + rand.Int()%(7-int(today.Weekday())))
-			durationUntilWeekday := time.Duration(weekday-today.Weekday()) * 24 * time.Hour
+	s.Assert("`Job.Weeks()` runs on the current day of the week of the clock", func(log sugar.Log) bool {
+		s := NewScheduler(WithClock(NewFakeClock(today))).(*scheduler)
+		job := s.Every(1).Weeks().At(aMinuteFromNowAtTime).Do(task)
+		s.RunPending()
+		return job.nextRun.Equal(aMinuteFromNow)
+	})
 
-			// create and init the job
-			job := newJob(interval).Weekday(weekday).At(aMinuteFromNowAtTime)
-			job.init(today)
+	s.Title("Time")
 
-			// jobs last run was interval weeks ago
-			aMinuteFromNowIntervalWeeksAgo := aMinuteFromNow.Add(durationUntilWeekday).Add(-1 * Week * time.Duration(interval))
-			if !job.lastRun.Equal(aMinuteFromNowIntervalWeeksAgo) {
-				log("the lastRun did not occur %d weeks ago", interval)
-				log(weekday, aMinuteFromNowIntervalWeeksAgo.Weekday(), job.nextRun.Weekday(), job.lastRun, aMinuteFromNowIntervalWeeksAgo)
-				return false
+	units := []struct {
+		name  string
+		unit  func(*Job) *Job
+		every time.Duration
+	}{
+		{"Hour", (*Job).Hours, time.Hour},
+		{"Minute", (*Job).Minutes, time.Minute},
+		{"Second", (*Job).Seconds, time.Second},
+	}
+	for _, unit := range units {
+		unit := unit
+		s.Assert(fmt.Sprintf("`Job.%s()` causes lastRun to be now and nextRun to be `interval` %s(s) from now", unit.name, strings.ToLower(unit.name)), func(log sugar.Log) bool {
+			for interval := uint64(1); interval <= 5; interval++ {
+				job := unit.unit(newJob(interval))
+				job.init(today)
+				if expected := today.Add(time.Duration(interval) * unit.every); !job.lastRun.Equal(today) || !job.nextRun.Equal(expected) {
+					log("expected %v and %v, got %v and %v", today, expected, job.lastRun, job.nextRun)
+					return false
+				}
 			}
+			return true
+		})
+	}
+}
 
-			// jobs next run is this week
-			thisWeekdayAMinuteFromNow := aMinuteFromNow.Add(durationUntilWeekday)
-			if !job.nextRun.Equal(thisWeekdayAMinuteFromNow) {
-				log("the nextRun will not occur this week")
-				log(weekday, thisWeekdayAMinuteFromNow.Weekday(), job.nextRun.Weekday(), job.nextRun, thisWeekdayAMinuteFromNow)
-				return false
-			}
+// counter counts the runs of tasks by name
+type counter struct {
+	mutex sync.Mutex
+	runs  map[string]int
+}
 
-			// after run, the nextRun is interval weeks from the previous nextRun
-			job.run()
-			aMinutAgoIntervalWeeksAfterNextRun := thisWeekdayAMinuteFromNow.Add(Week * time.Duration(interval))
-			if !job.nextRun.Equal(aMinutAgoIntervalWeeksAfterNextRun) {
-				log("the next nextRun will not happen in %d weeks", interval)
-				log(job.nextRun, aMinutAgoIntervalWeeksAfterNextRun)
-				return false
-			}
-		}
+func newCounter() *counter {
+	return &counter{runs: make(map[string]int)}
+}
 
-		return true
-	})
+func (c *counter) task(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.runs[name]++
+}
 
-	s.Title("Time")
+func (c *counter) count(name string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.runs[name]
+}
 
-	s.Assert("`Job.Hour()` causes lastRun to be now and nextRun to be `interval` hour(s) from now", func(log sugar.Log) bool {
-		// TODO: implement test
-		return false
-	})
+// eventually polls the condition until it holds, e.g. until a listener was notified
+// of the events, which are delivered shortly after they happen. It returns false
+// if the condition doesn't hold within 5 seconds
+func eventually(condition func() bool) bool {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if condition() {
+			return true
+		}
+	}
+	return condition()
+}
 
-	s.Assert("`Job.Minute()` causes lastRun to be now and nextRun to be `interval` minute(s) from now", func(log sugar.Log) bool {
-		// TODO: implement test
-		return false
-	})
+// releaseOnShutdown closes `release` once `Shutdown` waits for the in-progress runs to finish
+func releaseOnShutdown(s *scheduler, release chan bool) {
+	go func() {
+		eventually(func() bool {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			return s.drained != nil
+		})
+		close(release)
+	}()
+}
 
-	s.Assert("`Job.Second()` causes lastRun to be now and nextRun to be `interval` second(s) from now", func(log sugar.Log) bool {
-		// TODO: implement test
-		return false
-	})
+// newFakeScheduler returns a scheduler on a fake clock set to Wednesday, 2016-01-06 10:20:30
+func newFakeScheduler() (*scheduler, *FakeClock) {
+	clock := NewFakeClock(time.Date(2016, time.January, 6, 10, 20, 30, 0, time.Local))
+	return NewScheduler(WithClock(clock)).(*scheduler), clock
 }
 
-*/
+// tick advances the clock of a started scheduler a second at a time, and waits
+// for the loop to go back to sleep and the runs it started to finish
+func tick(s *scheduler, clock *FakeClock, seconds int) {
+	for i := 0; i < seconds; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		clock.BlockUntil(1)
+		s.running.Wait()
+	}
+}
 
 func TestScheduler(t *testing.T) {
 
 	s := sugar.New(t)
 
-	/*
-		s.Assert("`runPending(...)` runs all pending jobs", func(log sugar.Log) bool {
-			// TODO: implement test
+	s.Title("Scheduler")
+
+	s.Assert("`runPending(...)` runs all pending jobs", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.Every(1).Second().Do(c.task, "1s")
+		s.Every(2).Seconds().Do(c.task, "2s")
+		s.Every(1).Minute().Do(c.task, "1m")
+
+		// the first call initializes the jobs
+		s.RunPending()
+		for i := 0; i < 4; i++ {
+			clock.Advance(time.Second)
+			s.RunPending()
+		}
+		return c.count("1s") == 4 && c.count("2s") == 2 && c.count("1m") == 0
+	})
+
+	s.Assert("`Start()`, `IsRunning()` and `Stop()` perform correctly in asynchrnous environments", func(log sugar.Log) bool {
+		s, _ := newFakeScheduler()
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				s.Start()
+				s.IsRunning()
+			}()
+			go func() {
+				defer wg.Done()
+				s.Stop()
+			}()
+		}
+		wg.Wait()
+
+		s.Start()
+		if !s.IsRunning() {
+			log("the scheduler isn't running after `Start()`")
 			return false
-		})
+		}
+		s.Stop()
+		return !s.IsRunning()
+	})
 
-		s.Assert("`Start()`, `IsRunning()` and `Stop()` perform correctly in asynchrnous environments", func(log sugar.Log) bool {
-			// TODO: implement test
+	s.Assert("`Start()` runs the jobs when they are due", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.Every(1).Second().Do(c.task, "1s")
+		s.Every(3).Seconds().Do(c.task, "3s")
+		s.Start()
+		defer s.Stop()
+
+		tick(s, clock, 6)
+		return c.count("1s") == 6 && c.count("3s") == 2
+	})
+
+	s.Assert("`RunAllWithDelay(...)` waits for the delay on the clock of the scheduler", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.Every(1).Minute().Do(c.task, "a")
+		s.Every(1).Minute().Do(c.task, "b")
+
+		done := make(chan bool)
+		go func() {
+			s.RunAllWithDelay(time.Hour)
+			close(done)
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+		clock.BlockUntil(1)
+		select {
+		case <-done:
+			log("`RunAllWithDelay(...)` returned before the last delay")
 			return false
-		})
+		default:
+		}
+		clock.Advance(time.Hour)
 
-		s.Assert("`Start()` triggers runPending(...) every second", func(log sugar.Log) bool {
-			// TODO: implement test
+		select {
+		case <-done:
+			return c.count("a") == 1 && c.count("b") == 1
+		case <-time.After(5 * time.Second):
+			log("`RunAllWithDelay(...)` didn't return")
 			return false
-		})
-	*/
+		}
+	})
 
 	s.Title("Job order test")
 
 	s.Assert("`RemoveWithName()` should not raise a deadlock", func(log sugar.Log) bool {
-		s := scheduler{
-			jobMap:    make(map[string]*Job),
-			isStopped: make(chan bool),
-			location:  time.Local,
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.EveryWithName(1, "hello").Seconds().Do(c.task, "1s-hello")
+		s.EveryWithName(1, "world").Seconds().Do(c.task, "1s-world")
+		s.EveryWithName(2, "hello").Seconds().Do(c.task, "2s-hello")
+		s.Start()
+		defer s.Stop()
+
+		tick(s, clock, 3)
+		if !s.RemoveWithName("world") || s.RemoveWithName("world") {
+			log("the job was not removed once")
+			return false
 		}
-		s.EveryWithName(1, "hello").Seconds().Do(taskWithParams, 1, "1s-hello")
-		s.EveryWithName(1, "world").Seconds().Do(taskWithParams, 1, "1s-world")
-		s.EveryWithName(2, "hello").Seconds().Do(taskWithParams, 1, "2s-hello")
-		fmt.Println("Enable scheduler")
+		tick(s, clock, 3)
+
+		log("%v", c.runs)
+		return c.count("1s-hello") == 0 && c.count("1s-world") == 3 && c.count("2s-hello") == 3 && s.Len() == 1
+	})
+
+	s.Assert("`EveryWithName()` should update interval", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.EveryWithName(2, "hello").Seconds().Do(c.task, "hello")
+		s.EveryWithName(2, "world").Seconds().Do(c.task, "2s-world")
+		s.Start()
+		defer s.Stop()
 
-		for i, v := range s.jobMap {
-			fmt.Println(i, v.enabled)
+		tick(s, clock, 4)
+		s.EveryWithName(3, "world").Seconds().Do(c.task, "3s-world")
+		if s.UpdateIntervalWithName("hello1", 3) == nil || s.UpdateIntervalWithName("hello", 1) != nil {
+			log("unexpected update")
+			return false
 		}
+		tick(s, clock, 6)
 
-		fmt.Println(len(s.jobs))
+		log("%v", c.runs)
+		return c.count("hello") == 2+5 && c.count("2s-world") == 2 && c.count("3s-world") == 2 && s.Len() == 2
+	})
 
+	s.Assert("`Pause()` and `Resume()` should work", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.EveryWithName(2, "hello").Seconds().Do(c.task, "hello")
+		s.EveryWithName(2, "world").Seconds().Do(c.task, "world")
 		s.Start()
-		time.Sleep(3 * time.Second)
-		fmt.Println("RemoveWithName")
-		s.RemoveWithName("world")
+		defer s.Stop()
 
-		time.Sleep(3 * time.Second)
+		tick(s, clock, 4)
+		s.PauseWithName("hello")
+		tick(s, clock, 10)
+		s.ResumeWithName("hello")
+		tick(s, clock, 10)
 
-		for i, v := range s.jobMap {
-			fmt.Println(i, v.enabled)
-		}
+		log("%v", c.runs)
+		return c.count("hello") == 2+5 && c.count("world") == 12
+	})
 
-		fmt.Println(len(s.jobs))
+	s.Assert("`PauseAll()` and `ResumeAll()` should work", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.EveryWithName(2, "hello").Seconds().Do(c.task, "hello")
+		s.EveryWithName(2, "world").Seconds().Do(c.task, "world")
+		s.Start()
+		defer s.Stop()
 
-		time.Sleep(2 * time.Second)
-		s.Stop()
-		return true
+		tick(s, clock, 4)
+		s.PauseAll()
+		tick(s, clock, 10)
+		s.ResumeAll()
+		tick(s, clock, 10)
+
+		log("%v", c.runs)
+		return c.count("hello") == 2+5 && c.count("world") == 2+5
 	})
 
-	s.Assert("`EveryWithName()` should update interval", func(log sugar.Log) bool {
-		s := scheduler{
-			jobMap:    make(map[string]*Job),
-			isStopped: make(chan bool),
-			location:  time.Local,
-		}
-		s.EveryWithName(2, "hello").Seconds().Do(taskWithParams, 2, "2s-hello")
-		s.EveryWithName(2, "world").Seconds().Do(taskWithParams, 2, "2s-world")
-		fmt.Println("Enable scheduler")
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
-			}
-		*/
+	s.Assert("`Every()` should append job with order", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		s.Every(3).Seconds().Do(c.task, "3s")
+		s.Every(2).Seconds().Do(c.task, "2s")
+		s.Every(5).Seconds().Do(c.task, "5s")
+		s.EveryWithName(1, "hello").Seconds().Do(c.task, "1s-4")
+		s.EveryWithName(1, "world").Seconds().Do(c.task, "1s-5")
+		s.Every(500).Seconds().Do(c.task, "500s")
+		s.Every(10).Seconds().Do(c.task, "10s")
+		s.Start()
+		defer s.Stop()
 
-		for i, v := range s.jobMap {
-			fmt.Println(i, v.interval)
+		s.Emergency().Do(c.task, "emergency")
+		tick(s, clock, 5)
+		s.Emergency().Do(c.task, "emergency")
+		tick(s, clock, 5)
+
+		// the jobs are ordered by next run, then by interval
+		var intervals []uint64
+		for _, job := range s.Jobs() {
+			intervals = append(intervals, job.interval)
 		}
+		log("%v %v", intervals, c.runs)
+		return fmt.Sprint(intervals) == "[1 1 2 3 5 10 500]" && c.count("emergency") == 2 && c.count("10s") == 1
+	})
 
-		s.Start()
-		time.Sleep(4 * time.Second)
-		fmt.Println("update job `world` from 2 secs to 3 secs", time.Now().Format("2006-01-02 15:04:05.000"))
-		s.EveryWithName(3, "world").Seconds().Do(taskWithParams, 3, "3s-world")
-		s.UpdateIntervalWithName("hello1", 3)
-		s.UpdateIntervalWithName("hello", 1)
-		time.Sleep(3 * time.Second)
+	s.Assert("`Remove()` should delete desired job", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+
+		// add three jobs
+		s.Every(3).Seconds().Do(c.task, "3s")
+		item := s.Every(2).Seconds().Do(c.task, "2s")
+		s.Every(1).Seconds().Do(c.task, "1s")
 
-		for i, v := range s.jobMap {
-			fmt.Println(i, v.interval)
+		// remove one job
+		if !s.Remove(item) || s.Remove(item) || s.Len() != 2 {
+			log("the job was not removed once")
+			return false
 		}
 
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
+		s.RunPending()
+		clock.Advance(6 * time.Second)
+		s.RunPending()
+		return c.count("2s") == 0 && c.count("1s") == 1 && c.count("3s") == 1
+	})
+}
+
+func TestCron(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Cron expression")
+
+	// Wednesday, 2016-01-06 10:20:30
+	now := time.Date(2016, time.January, 6, 10, 20, 30, 0, time.UTC)
+
+	s.Assert("`parseCron(...)` computes the next matching time", func(log sugar.Log) bool {
+		tests := []struct {
+			expr string
+			next time.Time
+		}{
+			{"* * * * *", time.Date(2016, time.January, 6, 10, 21, 0, 0, time.UTC)},
+			{"*/15 * * * * *", time.Date(2016, time.January, 6, 10, 20, 45, 0, time.UTC)},
+			{"30 2 * * MON-FRI", time.Date(2016, time.January, 7, 2, 30, 0, 0, time.UTC)},
+			{"0 9,18 * * sat,sun", time.Date(2016, time.January, 9, 9, 0, 0, 0, time.UTC)},
+			{"0 0 29 2 *", time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC)},
+			{"0 0 1 */3 *", time.Date(2016, time.April, 1, 0, 0, 0, 0, time.UTC)},
+			{"0 12 13 * 5", time.Date(2016, time.January, 8, 12, 0, 0, 0, time.UTC)},
+			{"0 0 * * 7", time.Date(2016, time.January, 10, 0, 0, 0, 0, time.UTC)},
+			{"@hourly", time.Date(2016, time.January, 6, 11, 0, 0, 0, time.UTC)},
+			{"@daily", time.Date(2016, time.January, 7, 0, 0, 0, 0, time.UTC)},
+			{"@weekly", time.Date(2016, time.January, 10, 0, 0, 0, 0, time.UTC)},
+			{"@monthly", time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)},
+			{"@yearly", time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		}
+		for _, test := range tests {
+			cron, err := parseCron(test.expr)
+			if err != nil {
+				log("%q: %v", test.expr, err)
+				return false
 			}
-		*/
-		fmt.Println("job lengh", len(s.jobs))
-		time.Sleep(10 * time.Second)
-		s.Stop()
+			if next := cron.Next(now); !next.Equal(test.next) {
+				log("%q: expected %v, got %v", test.expr, test.next, next)
+				return false
+			}
+		}
 		return true
 	})
 
-	s.Assert("`Pause()` and `Resume()` should work", func(log sugar.Log) bool {
-		s := scheduler{
-			jobMap:    make(map[string]*Job),
-			isStopped: make(chan bool),
-			location:  time.Local,
+	s.Assert("`parseCron(...)` evaluates the expression in the location of the time", func(log sugar.Log) bool {
+		taipei := time.FixedZone("CST", 8*60*60)
+		cron, _ := parseCron("0 9 * * *")
+		next := cron.Next(now.In(taipei))
+		expected := time.Date(2016, time.January, 7, 9, 0, 0, 0, taipei)
+		if !next.Equal(expected) {
+			log("expected %v, got %v", expected, next)
+			return false
 		}
-		s.EveryWithName(2, "hello").Seconds().Do(taskWithParams, 2, "2s-hello")
-		s.EveryWithName(2, "world").Seconds().Do(taskWithParams, 2, "2s-world")
-		fmt.Println("Enable scheduler")
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
-			}
-		*/
+		return true
+	})
 
-		s.Start()
-		time.Sleep(4 * time.Second)
-		fmt.Println("pause job `hello`", time.Now().Format("2006-01-02 15:04:05.000"))
-		s.PauseWithName("hello")
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
-			}
-		*/
-		time.Sleep(10 * time.Second)
-		fmt.Println("resume job `hello`", time.Now().Format("2006-01-02 15:04:05.000"))
-		s.ResumeWithName("hello")
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
+	s.Assert("`parseCron(...)` rejects invalid expressions", func(log sugar.Log) bool {
+		for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * * * *", "5-1 * * * *", "*/0 * * * *", "0 0 30 2 *", "@never", "* * * FOO *"} {
+			if _, err := parseCron(expr); !errors.Is(err, ErrCronExpressionNotValid) {
+				log("%q: expected ErrCronExpressionNotValid, got %v", expr, err)
+				return false
 			}
-		*/
-		time.Sleep(10 * time.Second)
-		s.Stop()
+		}
 		return true
 	})
+}
 
-	s.Assert("`PauseAll()` and `ResumeAll()` should work", func(log sugar.Log) bool {
-		s := scheduler{
-			jobMap:    make(map[string]*Job),
-			isStopped: make(chan bool),
-			location:  time.Local,
+// everyOtherHour is a custom schedule that runs at the top of every even hour
+type everyOtherHour struct{}
+
+func (everyOtherHour) Next(after time.Time) time.Time {
+	next := after.Truncate(time.Hour).Add(time.Hour)
+	if next.Hour()%2 != 0 {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+func TestSchedule(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Schedule")
+
+	// Wednesday, 2016-01-06 10:20:30
+	now := time.Date(2016, time.January, 6, 10, 20, 30, 0, time.UTC)
+
+	s.Assert("built-in schedules compute the first and following runs", func(log sugar.Log) bool {
+		tests := []struct {
+			job    *Job
+			first  time.Time
+			second time.Time
+		}{
+			{
+				newJob(5).Seconds(),
+				now.Add(5 * time.Second),
+				now.Add(10 * time.Second),
+			},
+			{
+				newJob(2).Hours(),
+				now.Add(2 * time.Hour),
+				now.Add(4 * time.Hour),
+			},
+			{
+				newJob(2).Days().At("11:00"),
+				time.Date(2016, time.January, 6, 11, 0, 0, 0, time.UTC),
+				time.Date(2016, time.January, 8, 11, 0, 0, 0, time.UTC),
+			},
+			{
+				newJob(1).Day().At("09:00"),
+				time.Date(2016, time.January, 7, 9, 0, 0, 0, time.UTC),
+				time.Date(2016, time.January, 8, 9, 0, 0, 0, time.UTC),
+			},
+			{
+				newJob(1).Day(),
+				now.Add(Day),
+				now.Add(2 * Day),
+			},
+			{
+				newJob(1).Friday().At("09:00"),
+				time.Date(2016, time.January, 8, 9, 0, 0, 0, time.UTC),
+				time.Date(2016, time.January, 15, 9, 0, 0, 0, time.UTC),
+			},
+			{
+				newJob(2).Monday().At("09:00"),
+				time.Date(2016, time.January, 18, 9, 0, 0, 0, time.UTC),
+				time.Date(2016, time.February, 1, 9, 0, 0, 0, time.UTC),
+			},
+			{
+				newJob(1).Wednesday().At("09:00"),
+				time.Date(2016, time.January, 13, 9, 0, 0, 0, time.UTC),
+				time.Date(2016, time.January, 20, 9, 0, 0, 0, time.UTC),
+			},
 		}
-		s.EveryWithName(2, "hello").Seconds().Do(taskWithParams, 2, "2s-hello")
-		s.EveryWithName(2, "world").Seconds().Do(taskWithParams, 2, "2s-world")
-		fmt.Println("Enable scheduler")
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
+		for i, test := range tests {
+			job := test.job.Location(time.UTC)
+			job.init(now)
+			if !job.nextRun.Equal(test.first) {
+				log("%d: expected first run at %v, got %v", i, test.first, job.nextRun)
+				return false
 			}
-		*/
-
-		s.Start()
-		time.Sleep(4 * time.Second)
-		fmt.Println("pause all jobs", time.Now().Format("2006-01-02 15:04:05.000"))
-		s.PauseAll()
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
+			job.run()
+			if !job.nextRun.Equal(test.second) {
+				log("%d: expected second run at %v, got %v", i, test.second, job.nextRun)
+				return false
 			}
-		*/
-		time.Sleep(10 * time.Second)
-		fmt.Println("resume all job", time.Now().Format("2006-01-02 15:04:05.000"))
-		s.ResumeAll()
-		/*
-			for i, v := range s.jobMap {
-				fmt.Println(i, v.enabled)
+		}
+		return true
+	})
+
+	s.Assert("`At(...)` and `Weekday(...)` accumulate the slots of a job", func(log sugar.Log) bool {
+		tests := []struct {
+			job  *Job
+			runs []string
+		}{
+			{
+				newJob(1).Day().At("18:00", "09:00").At("13:00", "09:00"),
+				[]string{"2016-01-06 13:00", "2016-01-06 18:00", "2016-01-07 09:00", "2016-01-07 13:00"},
+			},
+			{
+				newJob(2).Days().At("09:00", "10:00"),
+				[]string{"2016-01-08 09:00", "2016-01-08 10:00", "2016-01-10 09:00"},
+			},
+			{
+				newJob(1).Monday().Wednesday().Friday().At("09:00", "18:00"),
+				[]string{"2016-01-06 18:00", "2016-01-08 09:00", "2016-01-08 18:00", "2016-01-11 09:00"},
+			},
+			{
+				newJob(2).Weekdays(time.Tuesday, time.Thursday).At("12:00"),
+				[]string{"2016-01-07 12:00", "2016-01-19 12:00", "2016-01-21 12:00", "2016-02-02 12:00"},
+			},
+			{
+				newJob(1).Month().DayOfMonth(15).At("08:00", "20:00"),
+				[]string{"2016-01-15 08:00", "2016-01-15 20:00", "2016-02-15 08:00"},
+			},
+		}
+		for i, test := range tests {
+			job := test.job.Location(time.UTC)
+			job.init(now)
+			for _, run := range test.runs {
+				if got := job.nextRun.Format("2006-01-02 15:04"); got != run {
+					log("%d: expected run at %s, got %s", i, run, got)
+					return false
+				}
+				job.run()
 			}
-		*/
-		time.Sleep(10 * time.Second)
-		s.Stop()
+		}
 		return true
 	})
 
-	s.Assert("`Every()` should append job with order", func(log sugar.Log) bool {
+	s.Assert("`Job.Schedule(...)` delegates to a custom schedule", func(log sugar.Log) bool {
+		job := newJob(1).Schedule(everyOtherHour{}).Location(time.UTC)
+		job.init(now)
+		if expected := time.Date(2016, time.January, 6, 12, 0, 0, 0, time.UTC); !job.nextRun.Equal(expected) {
+			log("expected %v, got %v", expected, job.nextRun)
+			return false
+		}
+		job.run()
+		if expected := time.Date(2016, time.January, 6, 14, 0, 0, 0, time.UTC); !job.nextRun.Equal(expected) {
+			log("expected %v, got %v", expected, job.nextRun)
+			return false
+		}
+		return true
+	})
 
+	s.Assert("`UpdateIntervalWithName(...)` updates the built-in schedule", func(log sugar.Log) bool {
 		s := scheduler{
-			jobMap:    make(map[string]*Job),
-			isStopped: make(chan bool),
-			location:  time.Local,
+			jobMap:   make(map[string]*Job),
+			location: time.UTC,
+		}
+		job := s.EveryWithName(1, "hello").Minutes().Do(task)
+		job.init(now)
+		if err := s.UpdateIntervalWithName("hello", 3); err != nil {
+			log("%v", err)
+			return false
+		}
+		job.run()
+		if expected := now.Add(4 * time.Minute); !job.nextRun.Equal(expected) {
+			log("expected %v, got %v", expected, job.nextRun)
+			return false
 		}
+		return true
+	})
+}
 
-		s.Every(3).Seconds().Do(taskWithParams, 1, "3s")
-		s.Every(2).Seconds().Do(taskWithParams, 2, "2s")
-		s.Every(5).Seconds().Do(taskWithParams, 3, "5s")
-		s.EveryWithName(1, "hello").Seconds().Do(taskWithParams, 4, "1s-4")
-		s.EveryWithName(1, "world").Seconds().Do(taskWithParams, 5, "1s-5")
-		s.Every(500).Seconds().Do(taskWithParams, 6, "500s")
+func TestMonthlySchedule(t *testing.T) {
 
-		s.Every(10).Seconds().Do(taskWithParams, 7, "10s")
-		for _, job := range s.jobs {
-			log("@interval: %d, param: %s", job.interval, job.tasksParams[0])
-		}
+	s := sugar.New(t)
 
-		for i, v := range s.jobMap {
-			fmt.Printf("map: %s, %p\n", i, v)
+	s.Title("Monthly schedule")
+
+	s.Assert("`Months()` with `DayOfMonth(...)` and `LastDayOfMonth()` handle short months and leap years", func(log sugar.Log) bool {
+		tests := []struct {
+			job  *Job
+			from time.Time
+			runs []string
+		}{
+			{
+				newJob(1).Month().DayOfMonth(1).At("02:00"),
+				time.Date(2016, time.January, 6, 10, 0, 0, 0, time.UTC),
+				[]string{"2016-02-01 02:00", "2016-03-01 02:00", "2016-04-01 02:00"},
+			},
+			{
+				newJob(1).Month().DayOfMonth(31).At("12:00"),
+				time.Date(2016, time.January, 6, 10, 0, 0, 0, time.UTC),
+				[]string{"2016-01-31 12:00", "2016-02-29 12:00", "2016-03-31 12:00", "2016-04-30 12:00"},
+			},
+			{
+				newJob(1).Month().LastDayOfMonth().At("23:00"),
+				time.Date(2015, time.January, 31, 23, 0, 0, 0, time.UTC),
+				[]string{"2015-02-28 23:00", "2015-03-31 23:00", "2015-04-30 23:00"},
+			},
+			{
+				newJob(1).Month().DayOfMonth(-3).At("08:00"),
+				time.Date(2016, time.January, 6, 10, 0, 0, 0, time.UTC),
+				[]string{"2016-01-28 08:00", "2016-02-26 08:00", "2016-03-28 08:00"},
+			},
+			{
+				newJob(3).Months().DayOfMonth(15).At("00:00"),
+				time.Date(2016, time.November, 20, 10, 0, 0, 0, time.UTC),
+				[]string{"2017-02-15 00:00", "2017-05-15 00:00"},
+			},
+			{
+				newJob(1).Months().At("09:30"),
+				time.Date(2016, time.January, 31, 10, 0, 0, 0, time.UTC),
+				[]string{"2016-02-29 09:30", "2016-03-31 09:30"},
+			},
+		}
+		for i, test := range tests {
+			job := test.job.Location(time.UTC)
+			job.init(test.from)
+			for _, run := range test.runs {
+				if got := job.nextRun.Format("2006-01-02 15:04"); got != run {
+					log("%d: expected run at %s, got %s", i, run, got)
+					return false
+				}
+				job.run()
+			}
 		}
+		return true
+	})
 
-		s.Start()
+	s.Assert("`NthWeekday(...)`, `LastWeekday(...)` and `Quarters()` select weekdays of the month or quarter", func(log sugar.Log) bool {
+		taipei := time.FixedZone("CST", 8*60*60)
+		tests := []struct {
+			job  *Job
+			from time.Time
+			runs []string
+		}{
+			{
+				newJob(1).Month().NthWeekday(2, time.Tuesday).At("09:00").Location(time.UTC),
+				time.Date(2016, time.January, 6, 10, 0, 0, 0, time.UTC),
+				[]string{"2016-01-12 09:00", "2016-02-09 09:00", "2016-03-08 09:00"},
+			},
+			{
+				newJob(1).Month().LastWeekday(time.Friday).At("17:00").Location(time.UTC),
+				time.Date(2016, time.January, 29, 18, 0, 0, 0, time.UTC),
+				[]string{"2016-02-26 17:00", "2016-03-25 17:00", "2016-04-29 17:00"},
+			},
+			{
+				newJob(1).Month().NthWeekday(-2, time.Sunday).At("00:00").Location(time.UTC),
+				time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC),
+				[]string{"2016-01-24 00:00", "2016-02-21 00:00"},
+			},
+			{
+				newJob(1).Quarter().LastWeekday(time.Friday).At("17:00").Location(time.UTC),
+				time.Date(2016, time.February, 10, 0, 0, 0, 0, time.UTC),
+				[]string{"2016-03-25 17:00", "2016-06-24 17:00", "2016-09-30 17:00", "2016-12-30 17:00"},
+			},
+			{
+				newJob(2).Quarters().DayOfMonth(1).At("06:00").Location(time.UTC),
+				time.Date(2016, time.May, 10, 0, 0, 0, 0, time.UTC),
+				[]string{"2016-10-01 06:00", "2017-04-01 06:00"},
+			},
+			{
+				newJob(1).Quarter().LastDayOfMonth().At("23:59").Location(time.UTC),
+				time.Date(2016, time.January, 10, 0, 0, 0, 0, time.UTC),
+				[]string{"2016-03-31 23:59", "2016-06-30 23:59"},
+			},
+			{
+				newJob(1).Month().NthWeekday(1, time.Monday).At("08:00").Location(taipei),
+				time.Date(2016, time.January, 31, 23, 0, 0, 0, time.UTC),
+				[]string{"2016-02-01 08:00", "2016-03-07 08:00"},
+			},
+		}
+		for i, test := range tests {
+			job := test.job
+			job.init(test.from)
+			for _, run := range test.runs {
+				if got := job.nextRun.Format("2006-01-02 15:04"); got != run {
+					log("%d: expected run at %s, got %s", i, run, got)
+					return false
+				}
+				job.run()
+			}
+		}
+		return true
+	})
 
-		fmt.Println("add emergency job 8", time.Now().Format("2006-01-02 15:04:05.000"))
-		s.Emergency().Do(taskWithParams, 8, "emergency")
+	s.Assert("`NthWeekday(...)` records an error with an occurrence outside of a month", func(log sugar.Log) bool {
+		for _, n := range []int{0, 5, -5} {
+			if err := newJob(1).NthWeekday(n, time.Monday).Err(); !errors.Is(err, ErrNthWeekdayNotValid) {
+				log("%d: expected ErrNthWeekdayNotValid", n)
+				return false
+			}
+		}
+		return true
+	})
 
-		time.Sleep(5 * time.Second)
-		for _, job := range s.jobs {
-			log("@@interval: %d, param: %s", job.interval, job.tasksParams[0])
+	s.Assert("`DayOfMonth(...)` records an error with a day outside of a month", func(log sugar.Log) bool {
+		for _, day := range []int{0, 32, -31} {
+			if err := newJob(1).DayOfMonth(day).Err(); !errors.Is(err, ErrDayOfMonthNotValid) {
+				log("%d: expected ErrDayOfMonthNotValid", day)
+				return false
+			}
 		}
+		return true
+	})
+}
 
-		fmt.Println("add emergency job 9", time.Now().Format("2006-01-02 15:04:05.000"))
-		s.Emergency().Do(taskWithParams, 9, "emergency")
+func TestJobError(t *testing.T) {
+
+	s := sugar.New(t)
 
-		//s.Every(1).Seconds().Do(taskWithParams, 10, "1s-10")
-		time.Sleep(5 * time.Second)
+	s.Title("Job errors")
 
-		// debug
-		for _, job := range s.jobs {
-			log("interval: %d, param: %s", job.interval, job.tasksParams[0])
+	s.Assert("builder methods record the first invalid argument instead of panicking", func(log sugar.Log) bool {
+		s := scheduler{
+			jobMap:   make(map[string]*Job),
+			location: time.Local,
+		}
+		tests := []struct {
+			job *Job
+			err error
+		}{
+			{s.Every(0).Seconds().Do(task), ErrIntervalNotValid},
+			{s.EveryWithName(0, "zero").Seconds().Do(task), ErrIntervalNotValid},
+			{s.Every(1).Do(task), ErrIntervalNotValid},
+			{s.Every(1).Day().At("25:00").At("ab:cd").Do(task), ErrIncorrectTimeFormat},
+			{s.Every(1).Second().Do("task"), ErrTaskIsNotAFuncError},
+			{s.Every(1).Second().Do(taskWithParams, 1), ErrMissmatchedTaskParams},
+			{s.Every(1).Second().Do(taskWithParams, "1", "2"), ErrMissmatchedTaskParams},
+			{s.Every(1).Second().Do(taskWithParams, 1, nil), ErrMissmatchedTaskParams},
+			{s.Cron("* * *").Do(task), ErrCronExpressionNotValid},
+		}
+		for i, test := range tests {
+			if !errors.Is(test.job.Err(), test.err) {
+				log("%d: expected %v, got %v", i, test.err, test.job.Err())
+				return false
+			}
 		}
 
-		if s.jobs[1].interval == 1 {
-			s.Stop()
-			return true
+		// jobs with an error are never run
+		s.runPending(time.Now().Add(time.Hour))
+		for i, test := range tests {
+			if test.job.isInit() {
+				log("%d: job with an error was run", i)
+				return false
+			}
 		}
-		s.Stop()
-		return false
+		return true
 	})
 
-	s.Assert("`Remove()` should delete desired job", func(log sugar.Log) bool {
-		s := scheduler{
-			isStopped: make(chan bool),
-			location:  time.Local,
+	s.Assert("`At(...)` parses 24-hour and 12-hour times with seconds", func(log sugar.Log) bool {
+		valid := map[string]time.Duration{
+			"9:30":       9*time.Hour + 30*time.Minute,
+			"09:30":      9*time.Hour + 30*time.Minute,
+			"00:00":      0,
+			"23:59:59":   23*time.Hour + 59*time.Minute + 59*time.Second,
+			"7:05:09":    7*time.Hour + 5*time.Minute + 9*time.Second,
+			"9:30pm":     21*time.Hour + 30*time.Minute,
+			"9:30 PM":    21*time.Hour + 30*time.Minute,
+			"12:00am":    0,
+			"12:15pm":    12*time.Hour + 15*time.Minute,
+			"11:59:30am": 11*time.Hour + 59*time.Minute + 30*time.Second,
+			"9pm":        21 * time.Hour,
+		}
+		for t, expected := range valid {
+			atTime, err := parseAtTime(t)
+			if err != nil || atTime != expected {
+				log("%q: expected %v, got %v (%v)", t, expected, atTime, err)
+				return false
+			}
 		}
 
-		// add three jobs
-		s.Every(3).Seconds().Do(task)
-		item := s.Every(2).Seconds().Do(task2)
-		s.Every(1).Seconds().Do(task3)
+		invalid := []string{"", "9", "ab:cd", "24:00", "9:60", "9:5", "9:30:60", "9:30:5", "123:00", "1:2:3:4", "0:30pm", "13:00pm", "9:30xm", ":30", "-1:30", "+9:30"}
+		for _, t := range invalid {
+			if _, err := parseAtTime(t); !errors.Is(err, ErrIncorrectTimeFormat) {
+				log("%q: expected ErrIncorrectTimeFormat, got %v", t, err)
+				return false
+			}
+		}
 
-		// debug
-		for _, job := range s.jobs {
-			log("interval: %d", job.interval)
+		job := newJob(1).Day().At("9:30:15pm").Location(time.UTC)
+		job.init(time.Date(2016, time.January, 6, 10, 0, 0, 0, time.UTC))
+		if expected := time.Date(2016, time.January, 6, 21, 30, 15, 0, time.UTC); !job.nextRun.Equal(expected) {
+			log("expected %v, got %v", expected, job.nextRun)
+			return false
 		}
+		return true
+	})
 
-		// remove one job
-		s.Remove(item)
+	s.Assert("`JobError` describes the job and the invalid argument", func(log sugar.Log) bool {
+		s := scheduler{
+			jobMap:   make(map[string]*Job),
+			location: time.Local,
+		}
+		err := s.EveryWithName(1, "report").Day().At("25:00").Err()
+		jobErr, ok := err.(*JobError)
+		if !ok || jobErr.Job != "report" || jobErr.Op != "At" || jobErr.Arg != "25:00" {
+			log("unexpected error %#v", err)
+			return false
+		}
+		expected := `gocron: job "report": At("25:00"): the time format is incorrect: hour 25 is not between 0 and 23`
+		if err.Error() != expected {
+			log("expected %q, got %q", expected, err.Error())
+			return false
+		}
 
-		// debug
-		for _, job := range s.jobs {
-			log("@interval: %d", job.interval)
+		err = s.EveryWithName(0, "zero").Err()
+		if jobErr, ok := err.(*JobError); !ok || jobErr.Job != "zero" || jobErr.Op != "Every" {
+			log("unexpected error %#v", err)
+			return false
 		}
 
-		if s.Len() == 2 {
-			s.Stop()
-			return true
+		if s.EveryWithName(1, "valid").Seconds().Do(task).Err() != nil {
+			log("valid job recorded an error")
+			return false
 		}
-		s.Stop()
-		return false
+		if !errors.Is(s.UpdateIntervalWithName("valid", 0), ErrIntervalNotValid) {
+			log("updated the interval to 0")
+			return false
+		}
+		if !errors.Is(s.UpdateIntervalWithName("missing", 1), ErrJobNotFound) {
+			log("updated the interval of a missing job")
+			return false
+		}
+		custom := s.EveryWithName(1, "custom").Schedule(every(time.Second)).Do(task)
+		if !errors.Is(s.UpdateIntervalWithName("custom", 2), ErrScheduleHasNoInterval) || custom.interval != 1 {
+			log("updated the interval of a custom schedule")
+			return false
+		}
+		return true
 	})
+}
+
+func TestConcurrency(t *testing.T) {
 
+	s := sugar.New(t)
+
+	s.Title("Concurrency")
+
+	s.Assert("`WithMaxConcurrency(...)` bounds the number of concurrent runs", func(log sugar.Log) bool {
+		s := NewScheduler(WithMaxConcurrency(2)).(*scheduler)
+
+		started := make(chan bool, 5)
+		release := make(chan bool)
+		for i := 0; i < 5; i++ {
+			s.Every(1).Second().Do(func() {
+				started <- true
+				<-release
+			})
+		}
+
+		now := time.Now()
+		s.runPending(now)
+		dispatched := s.runPending(now.Add(time.Second))
+
+		// two runs start, the others wait for a free worker
+		for i := 0; i < 2; i++ {
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				log("only %d of the runs started", i)
+				return false
+			}
+		}
+		select {
+		case <-started:
+			log("more than 2 runs started")
+			return false
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// slow tasks don't hold the scheduler lock
+		if _, next := s.NextRun(); !next.Equal(now.Add(2 * time.Second)) {
+			log("expected the next run at %v, got %v", now.Add(2*time.Second), next)
+			return false
+		}
+
+		close(release)
+		dispatched.Wait()
+		if len(started) != 3 {
+			log("expected 3 more runs, got %d", len(started))
+			return false
+		}
+		return true
+	})
+
+	s.Assert("runs waiting for a worker don't have a goroutine of their own", func(log sugar.Log) bool {
+		s := NewScheduler(WithMaxConcurrency(2)).(*scheduler)
+
+		release := make(chan bool)
+		for i := 0; i < 1000; i++ {
+			s.Every(1).Second().Do(func() { <-release })
+		}
+
+		now := time.Now()
+		s.runPending(now)
+		before := runtime.NumGoroutine()
+		dispatched := s.runPending(now.Add(time.Second))
+		after := runtime.NumGoroutine()
+		close(release)
+		dispatched.Wait()
+		log("%d goroutines before, %d after", before, after)
+		return after-before <= 2
+	})
+
+	s.Assert("runs of different jobs overlap without a bound", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+
+		started := make(chan bool)
+		release := make(chan bool)
+		for i := 0; i < 3; i++ {
+			s.Every(1).Second().Do(func() {
+				started <- true
+				<-release
+			})
+		}
+
+		now := time.Now()
+		s.runPending(now)
+		dispatched := s.runPending(now.Add(time.Second))
+		for i := 0; i < 3; i++ {
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				log("only %d of the runs started", i)
+				return false
+			}
+		}
+		close(release)
+		dispatched.Wait()
+		return true
+	})
+
+	s.Assert("the scheduler is safe for concurrent use", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		stop := make(chan bool)
+
+		// move the clock forward so the run loop keeps running jobs
+		advanced := make(chan bool)
+		go func() {
+			defer close(advanced)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					clock.Advance(time.Second)
+				}
+			}
+		}()
+
+		done := make(chan bool)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for k := 0; k < 200; k++ {
+					name := fmt.Sprintf("job-%d", (i+k)%5)
+					switch k % 10 {
+					case 0:
+						s.EveryWithName(uint64(k%3+1), name).Seconds().Do(c.task, name)
+					case 1:
+						// build a job the scheduler already runs
+						job := s.Every(1).Second().Do(c.task, "every")
+						job.Misfire(MisfireSkip, time.Second).Location(time.UTC).Do(c.task, "every")
+					case 2:
+						s.RemoveWithName(name)
+					case 3:
+						s.PauseWithName(name)
+						s.ResumeWithName(name)
+					case 4:
+						s.UpdateIntervalWithName(name, uint64(k%4+1))
+					case 5:
+						s.Start()
+					case 6:
+						s.Stop()
+					case 7:
+						s.Location(time.UTC)
+						s.NextRun()
+						s.IsRunning()
+						s.Emergency().Do(c.task, "emergency")
+					case 8:
+						s.PauseAll()
+						s.ResumeAll()
+						s.AddListener(NopListener{})
+					case 9:
+						for _, job := range s.Jobs() {
+							job.Err()
+							job.IsPaused()
+							job.IsRunning()
+							if job.Name() == "" && k%20 == 9 {
+								s.Remove(job)
+							}
+						}
+					}
+				}
+			}(i)
+		}
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(20 * time.Second):
+			log("deadlock")
+			return false
+		}
+		close(stop)
+		<-advanced
+		s.Stop()
+		s.running.Wait()
+
+		// the queue and the job map are consistent
+		for i, job := range s.jobs {
+			if job.index != i {
+				log("job %d has index %d", i, job.index)
+				return false
+			}
+		}
+		for name, job := range s.jobMap {
+			if !s.jobs.contains(job) {
+				log("job %s isn't queued", name)
+				return false
+			}
+		}
+
+		// and the scheduler still runs jobs
+		s.Clear()
+		s.EveryWithName(1, "after").Seconds().Do(c.task, "after")
+		s.Start()
+		defer s.Stop()
+		tick(s, clock, 2)
+		log("%v", c.runs)
+		return c.count("after") == 2
+	})
+}
+
+// scheduledRecorder is a listener recording the time the runs were scheduled at
+type scheduledRecorder struct {
+	NopListener
+	mutex     sync.Mutex
+	scheduled []time.Time
+}
+
+func (r *scheduledRecorder) RunStarted(job *Job, scheduled time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.scheduled = append(r.scheduled, scheduled)
+}
+
+func TestOverlap(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Overlap policy")
+
+	// runOverlapping runs a job with the given policy 3 times while its first run blocks,
+	// and returns the number of times each of its two tasks was called.
+	// If `wait` is true, each run is due once the previous one is blocked
+	runOverlapping := func(policy OverlapPolicy, wait bool) (*Job, int, int) {
+		s := NewScheduler().(*scheduler)
+
+		started := make(chan bool, 3)
+		release := make(chan bool)
+		var mutex sync.Mutex
+		first, second := 0, 0
+		job := s.Every(1).Second().Overlap(policy).Do(func() {
+			mutex.Lock()
+			first++
+			mutex.Unlock()
+			started <- true
+			<-release
+		}).Do(func() {
+			mutex.Lock()
+			second++
+			mutex.Unlock()
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		var dispatched []*sync.WaitGroup
+		for i := 1; i <= 3; i++ {
+			dispatched = append(dispatched, s.runPending(now.Add(time.Duration(i)*time.Second)))
+			if i == 1 || wait {
+				<-started
+			}
+		}
+		close(release)
+		for _, wg := range dispatched {
+			wg.Wait()
+		}
+		return job, first, second
+	}
+
+	s.Assert("`OverlapAllow` runs every run in parallel", func(log sugar.Log) bool {
+		job, first, second := runOverlapping(OverlapAllow, true)
+		if first != 3 || second != 3 || job.SkippedRuns() != 0 {
+			log("expected 3 runs, got %d and %d", first, second)
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`OverlapSkip` drops and counts the runs due during a run", func(log sugar.Log) bool {
+		job, first, second := runOverlapping(OverlapSkip, false)
+		if first != 1 || second != 1 || job.SkippedRuns() != 2 {
+			log("expected 1 run and 2 skipped, got %d and %d with %d skipped", first, second, job.SkippedRuns())
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`OverlapQueue` runs the runs due during a run one after another", func(log sugar.Log) bool {
+		job, first, second := runOverlapping(OverlapQueue, false)
+		if first != 3 || second != 3 || job.SkippedRuns() != 0 || job.IsRunning() {
+			log("expected 3 runs, got %d and %d", first, second)
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`OverlapQueue` runs the queued runs with the time they were scheduled at", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		r := &scheduledRecorder{}
+		s.AddListener(r)
+
+		started := make(chan bool, 3)
+		release := make(chan bool)
+		s.Every(1).Second().Overlap(OverlapQueue).Do(func() {
+			started <- true
+			<-release
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		var dispatched []*sync.WaitGroup
+		for i := 1; i <= 3; i++ {
+			dispatched = append(dispatched, s.runPending(now.Add(time.Duration(i)*time.Second)))
+		}
+		<-started
+		close(release)
+		for _, wg := range dispatched {
+			wg.Wait()
+		}
+		eventually(func() bool {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			return len(r.scheduled) == 3
+		})
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		log("%v", r.scheduled)
+		for i, scheduled := range r.scheduled {
+			if !scheduled.Equal(now.Add(time.Duration(i+1) * time.Second)) {
+				return false
+			}
+		}
+		return len(r.scheduled) == 3
+	})
+
+	s.Assert("`OverlapReplace` cancels the previous run", func(log sugar.Log) bool {
+		_, first, second := runOverlapping(OverlapReplace, true)
+		if first != 3 || second != 1 {
+			log("expected 3 runs with 1 finished, got %d and %d", first, second)
+			return false
+		}
+		return true
+	})
+}
+
+func TestContext(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Context")
+
+	// runBlocking runs a job whose task waits for its context to be done, calls
+	// `cancel` once the task is running and returns the error of the context
+	runBlocking := func(timeout time.Duration, cancel func(s *scheduler, job *Job)) error {
+		s := NewScheduler().(*scheduler)
+
+		started := make(chan bool)
+		done := make(chan error, 1)
+		job := s.Every(1).Second().Timeout(timeout).Do(func(ctx context.Context, name string) {
+			started <- true
+			<-ctx.Done()
+			done <- ctx.Err()
+		}, "blocking")
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second))
+		<-started
+		cancel(s, job)
+
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	s.Assert("`Timeout(...)` cancels the context of the task when it elapses", func(log sugar.Log) bool {
+		err := runBlocking(10*time.Millisecond, func(s *scheduler, job *Job) {})
+		if err != context.DeadlineExceeded {
+			log("expected context.DeadlineExceeded, got %v", err)
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`Timeout(...)` fails the run when the remaining tasks are not called", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		events := &recorder{s: s}
+		s.AddListener(events)
+
+		var called int32
+		job := s.EveryWithName(1, "slow").Second().Timeout(10 * time.Millisecond).Do(func(ctx context.Context) { <-ctx.Done() })
+		job.Do(func() { atomic.AddInt32(&called, 1) })
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+
+		log("%s", events.wait(3))
+		if atomic.LoadInt32(&called) != 0 || !errors.Is(job.LastError(), context.DeadlineExceeded) {
+			log("expected the second task not to be called and context.DeadlineExceeded, got %d calls (%v)", called, job.LastError())
+			return false
+		}
+		return strings.Contains(events.recorded(), "failed slow")
+	})
+
+	s.Assert("`Timeout(...)` starts when the run gets a worker", func(log sugar.Log) bool {
+		s := NewScheduler(WithMaxConcurrency(1)).(*scheduler)
+
+		started := make(chan bool)
+		release := make(chan bool)
+		s.Every(1).Second().Do(func() {
+			started <- true
+			<-release
+		})
+		var deadline time.Time
+		job := s.Every(1).Second().Timeout(time.Hour).Do(func(ctx context.Context) {
+			deadline, _ = ctx.Deadline()
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		dispatched := s.runPending(now.Add(time.Second))
+		<-started
+		released := time.Now()
+		close(release)
+		dispatched.Wait()
+
+		// the deadline of the queued run is an hour after it got the worker
+		if deadline.Before(released.Add(time.Hour)) || job.LastError() != nil {
+			log("expected a deadline after %v, got %v (%v)", released.Add(time.Hour), deadline, job.LastError())
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`Remove(...)` cancels the context of the task", func(log sugar.Log) bool {
+		err := runBlocking(0, func(s *scheduler, job *Job) { s.Remove(job) })
+		if err != context.Canceled {
+			log("expected context.Canceled, got %v", err)
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`Stop()` cancels the context of the task", func(log sugar.Log) bool {
+		err := runBlocking(0, func(s *scheduler, job *Job) { s.Stop() })
+		if err != context.Canceled {
+			log("expected context.Canceled, got %v", err)
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`Do(...)` only injects the context when it isn't passed", func(log sugar.Log) bool {
+		var got context.Context
+		job := newJob(1).Second().Do(func(ctx context.Context) { got = ctx }, context.TODO())
+		job.init(time.Now())
+		job.run()
+		if job.Err() != nil || got != context.TODO() {
+			log("expected the context passed to `Do`, got %v (%v)", got, job.Err())
+			return false
+		}
+
+		if err := newJob(1).Second().Do(func(ctx context.Context, a int) {}).Err(); !errors.Is(err, ErrMissmatchedTaskParams) {
+			log("expected ErrMissmatchedTaskParams, got %v", err)
+			return false
+		}
+		return true
+	})
+}
+
+func TestShutdown(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Shutdown")
+
+	s.Assert("`Shutdown(...)` waits for the in-progress runs to finish", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+
+		started := make(chan bool)
+		release := make(chan bool)
+		var cancelled error
+		s.Every(1).Second().Do(func(ctx context.Context) {
+			started <- true
+			<-release
+			cancelled = ctx.Err()
+		})
+		s.Start()
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second))
+		<-started
+
+		type result struct {
+			jobs []*Job
+			err  error
+		}
+		shutdown := make(chan result)
+		go func() {
+			jobs, err := s.Shutdown(context.Background())
+			shutdown <- result{jobs, err}
+		}()
+
+		select {
+		case <-shutdown:
+			log("returned before the run finished")
+			return false
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+		r := <-shutdown
+		return r.err == nil && len(r.jobs) == 0 && cancelled == nil && !s.IsRunning()
+	})
+
+	s.Assert("`Shutdown(...)` cancels the runs and returns the running jobs when the context is done", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+
+		started := make(chan bool, 2)
+		done := make(chan error, 2)
+		blocking := func(ctx context.Context) {
+			started <- true
+			<-ctx.Done()
+			done <- ctx.Err()
+		}
+		job1 := s.Every(1).Second().Do(blocking)
+		s.Every(1).Second().Do(func() {})
+		job3 := s.Every(1).Second().Do(blocking)
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second))
+		<-started
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		jobs, err := s.Shutdown(ctx)
+		if err != context.DeadlineExceeded {
+			log("expected context.DeadlineExceeded, got %v", err)
+			return false
+		}
+		if len(jobs) != 2 || jobs[0] != job1 || jobs[1] != job3 {
+			log("expected the blocking jobs, got %v", jobs)
+			return false
+		}
+		return <-done == context.Canceled && <-done == context.Canceled
+	})
+
+	s.Assert("`Shutdown(...)` drops the queued runs", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+
+		var runs int32
+		started := make(chan bool)
+		release := make(chan bool)
+		s.Every(1).Second().Overlap(OverlapQueue).Do(func() {
+			atomic.AddInt32(&runs, 1)
+			started <- true
+			<-release
+		})
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second))
+		<-started
+		s.runPending(now.Add(2 * time.Second))
+
+		releaseOnShutdown(s, release)
+		jobs, err := s.Shutdown(context.Background())
+		return err == nil && len(jobs) == 0 && atomic.LoadInt32(&runs) == 1
+	})
+
+	s.Assert("`Shutdown(...)` drops and skips the runs waiting for a worker", func(log sugar.Log) bool {
+		s := NewScheduler(WithMaxConcurrency(1)).(*scheduler)
+		events := &recorder{s: s}
+		s.AddListener(events)
+
+		var runs int32
+		started := make(chan bool)
+		release := make(chan bool)
+		s.EveryWithName(1, "blocking").Second().Do(func() {
+			started <- true
+			<-release
+		})
+		s.EveryWithName(1, "waiting").Second().Do(func() {
+			atomic.AddInt32(&runs, 1)
+		})
+		now := time.Now()
+		s.runPending(now)
+		dispatched := s.runPending(now.Add(time.Second))
+		<-started
+
+		releaseOnShutdown(s, release)
+		jobs, err := s.Shutdown(context.Background())
+		dispatched.Wait()
+		recorded := events.wait(5)
+		log("%s", recorded)
+		return err == nil && len(jobs) == 0 && atomic.LoadInt32(&runs) == 0 &&
+			recorded == "[added blocking added waiting started blocking skipped waiting succeeded blocking]"
+	})
+}
+
+func TestTaskErrors(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Task errors")
+
+	errTask := errors.New("task failed")
+
+	s.Assert("a panicking task is recovered and reported with its stack trace", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		called := false
+		job := s.Every(1).Second().Do(func() {
+			panic(errTask)
+		}).Do(func() {
+			called = true
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+
+		err := job.LastError()
+		panicErr, ok := err.(*PanicError)
+		if !ok || !errors.Is(err, ErrTaskPanicked) || !errors.Is(err, errTask) || !bytes.Contains(panicErr.Stack, []byte("gocron_test.go")) {
+			log("unexpected error %v", err)
+			return false
+		}
+		if called {
+			log("the task after the panicking task was called")
+			return false
+		}
+
+		// the scheduler keeps running the job
+		s.runPending(now.Add(2 * time.Second)).Wait()
+		return errors.Is(job.LastError(), ErrTaskPanicked)
+	})
+
+	s.Assert("the error returned by a task is captured per job", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		fail := true
+		failing := s.Every(1).Second().Do(func(a int) (int, error) {
+			if fail {
+				return a, errTask
+			}
+			return a, nil
+		}, 1)
+		succeeding := s.Every(1).Second().Do(func() error { return nil })
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+		if failing.LastError() != errTask || succeeding.LastError() != nil {
+			log("unexpected errors %v and %v", failing.LastError(), succeeding.LastError())
+			return false
+		}
+
+		fail = false
+		s.runPending(now.Add(2 * time.Second)).Wait()
+		if failing.LastError() != nil {
+			log("the error of the previous run was kept: %v", failing.LastError())
+			return false
+		}
+		return true
+	})
+}
+
+func TestRetry(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Retry")
+
+	errTask := errors.New("task failed")
+
+	s.Assert("`Retry(...)` retries a failed run until it succeeds", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		calls := 0
+		job := s.Every(1).Second().Retry(RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}).Do(func() error {
+			calls++
+			if calls < 3 {
+				return errTask
+			}
+			return nil
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+		if calls != 3 || job.LastError() != nil {
+			log("unexpected %d calls and error %v", calls, job.LastError())
+			return false
+		}
+		return true
+	})
+
+	s.Assert("`Retry(...)` resumes a failed run from the failed task", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		first, second := 0, 0
+		job := s.Every(1).Second().Retry(RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}).Do(func() {
+			first++
+		}).Do(func() error {
+			second++
+			if second < 3 {
+				return errTask
+			}
+			return nil
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+		log("%d calls of the first task, %d calls of the second, error %v", first, second, job.LastError())
+		return first == 1 && second == 3 && job.LastError() == nil
+	})
+
+	s.Assert("`Retry(...)` gives up after the maximum number of attempts", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		calls := 0
+		job := s.Every(1).Second().Retry(RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond}).Do(func() error {
+			calls++
+			return errTask
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+		return calls == 2 && job.LastError() == errTask
+	})
+
+	s.Assert("`Retry(...)` doesn't retry past the next regular run", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		calls := 0
+		job := s.Every(1).Second().Retry(RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}).Do(func() error {
+			calls++
+			return errTask
+		})
+
+		// the next regular run is already due
+		now := time.Now().Add(-time.Hour)
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+		return calls == 1 && job.LastError() == errTask
+	})
+
+	s.Assert("the delay between retries grows exponentially up to the maximum delay", func(log sugar.Log) bool {
+		policy := RetryPolicy{InitialDelay: time.Second, Multiplier: 3, MaxDelay: 20 * time.Second}
+		expected := []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 20 * time.Second, 20 * time.Second}
+		for i, delay := range expected {
+			if actual := policy.delay(i + 1); actual != delay {
+				log("retry %d: expected %v, got %v", i+1, delay, actual)
+				return false
+			}
+		}
+
+		policy = RetryPolicy{InitialDelay: 10 * time.Second, Jitter: 0.1}
+		for i := 0; i < 100; i++ {
+			if delay := policy.delay(1); delay < 9*time.Second || delay > 11*time.Second {
+				log("jittered delay %v out of bounds", delay)
+				return false
+			}
+		}
+		return policy.delay(2) >= 18*time.Second
+	})
+}
+
+func TestMisfire(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Misfire policy")
+
+	// runMissed runs a job every minute 10 minutes after it was due,
+	// and returns the number of runs and the next run
+	runMissed := func(policy MisfirePolicy, threshold time.Duration) (*Job, int, time.Duration) {
+		s := NewScheduler().(*scheduler)
+		var mutex sync.Mutex
+		runs := 0
+		job := s.Every(1).Minute().Misfire(policy, threshold).Do(func() {
+			mutex.Lock()
+			runs++
+			mutex.Unlock()
+		})
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(10*time.Minute + 30*time.Second)).Wait()
+		return job, runs, job.nextRun.Sub(now)
+	}
+
+	s.Assert("`MisfireRunOnce` runs the missed runs once and realigns the next run", func(log sugar.Log) bool {
+		_, runs, next := runMissed(MisfireRunOnce, 0)
+		log("%d runs, next run in %v", runs, next)
+		return runs == 1 && next == 11*time.Minute
+	})
+
+	s.Assert("`MisfireRunAll` runs every missed run", func(log sugar.Log) bool {
+		_, runs, next := runMissed(MisfireRunAll, 0)
+		log("%d runs, next run in %v", runs, next)
+		return runs == 10 && next == 11*time.Minute
+	})
+
+	s.Assert("`MisfireRunAll` runs up to `MaxCatchUpRuns` missed runs and counts the older ones", func(log sugar.Log) bool {
+		now := time.Now()
+		for _, job := range []*Job{
+			newJob(1).Second().Misfire(MisfireRunAll, 0),
+			NewScheduler().Cron("* * * * * *").Misfire(MisfireRunAll, 0),
+		} {
+			job.init(now)
+			first := job.nextRun
+			runs := job.catchUp(first.Add(time.Hour + 500*time.Millisecond))
+			log("%d runs, %d missed runs, last run %v", len(runs), job.MissedRuns(), runs[len(runs)-1].Sub(first))
+			if len(runs) != MaxCatchUpRuns || job.MissedRuns() != 3601-MaxCatchUpRuns ||
+				runs[len(runs)-1].Sub(first) != time.Hour || job.nextRun.Sub(first) != time.Hour+time.Second {
+				return false
+			}
+		}
+		return true
+	})
+
+	s.Assert("schedules without a fixed interval catch up on a week of missed runs at once", func(log sugar.Log) bool {
+		now := time.Now()
+		week := 7 * 24 * time.Hour
+		for _, policy := range []MisfirePolicy{MisfireRunOnce, MisfireRunAll, MisfireSkip} {
+			var calls int64
+			job := newJob(1).Schedule(until{last: now.Add(2 * week), calls: &calls}).Misfire(policy, 0)
+			job.init(now)
+			first := job.nextRun
+			runs := job.catchUp(first.Add(week + 500*time.Millisecond))
+			log("policy %d: %d runs, %d missed runs, %d calls to Next", policy, len(runs), job.MissedRuns(), calls)
+			if calls > 4*MaxCatchUpRuns || job.nextRun.Sub(first) != week+time.Second {
+				return false
+			}
+			switch policy {
+			case MisfireRunOnce:
+				if len(runs) != 1 || runs[0] != first {
+					return false
+				}
+			case MisfireRunAll:
+				if len(runs) != MaxCatchUpRuns || runs[len(runs)-1].Sub(first) != week ||
+					job.MissedRuns() != uint64(week/time.Second)+1-MaxCatchUpRuns {
+					return false
+				}
+			case MisfireSkip:
+				if len(runs) != 0 || job.MissedRuns() != uint64(week/time.Second)+1 {
+					return false
+				}
+			}
+		}
+		return true
+	})
+
+	s.Assert("`MisfireSkip` drops and counts the missed runs", func(log sugar.Log) bool {
+		job, runs, next := runMissed(MisfireSkip, 0)
+		log("%d runs, %d missed runs, next run in %v", runs, job.MissedRuns(), next)
+		return runs == 0 && job.MissedRuns() == 10 && next == 11*time.Minute
+	})
+
+	s.Assert("runs within the misfire threshold run as usual", func(log sugar.Log) bool {
+		job, runs, next := runMissed(MisfireSkip, time.Hour)
+		log("%d runs, %d missed runs, next run in %v", runs, job.MissedRuns(), next)
+		return runs == 1 && job.MissedRuns() == 0 && next == 2*time.Minute
+	})
+
+	s.Assert("`MisfireSkip` realigns daily jobs on their next slot", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		job := s.Every(1).Day().At("10:30").Misfire(MisfireSkip, 0).Do(func() {})
+
+		now := time.Date(2016, 1, 6, 9, 0, 0, 0, time.Local)
+		s.runPending(now)
+		s.runPending(time.Date(2016, 1, 9, 12, 0, 0, 0, time.Local)).Wait()
+		expected := time.Date(2016, 1, 10, 10, 30, 0, 0, time.Local)
+		log("next run %v, %d missed runs", job.nextRun, job.MissedRuns())
+		return job.nextRun.Equal(expected) && job.MissedRuns() == 4
+	})
+}
+
+// recorder is a listener recording the events it is notified of
+type recorder struct {
+	NopListener
+	mutex  sync.Mutex
+	events []string
+	s      Scheduler
+}
+
+func (r *recorder) record(format string, a ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, a...))
+}
+
+func (r *recorder) recorded() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return fmt.Sprint(r.events)
+}
+
+// wait waits for the listener to record `n` events, and returns the recorded events
+func (r *recorder) wait(n int) string {
+	eventually(func() bool {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		return len(r.events) >= n
+	})
+	return r.recorded()
+}
+
+func (r *recorder) JobAdded(job *Job) {
+	// listeners can call the scheduler
+	r.s.NextRun()
+	r.record("added %s", job.Name())
+}
+
+func (r *recorder) JobRemoved(job *Job) { r.record("removed %s", job.Name()) }
+func (r *recorder) JobPaused(job *Job)  { r.record("paused %s", job.Name()) }
+func (r *recorder) JobResumed(job *Job) { r.record("resumed %s", job.Name()) }
+func (r *recorder) RunStarted(job *Job, scheduled time.Time) {
+	r.record("started %s", job.Name())
+}
+func (r *recorder) RunSkipped(job *Job) { r.record("skipped %s", job.Name()) }
+func (r *recorder) SchedulerStarted()   { r.record("scheduler started") }
+func (r *recorder) SchedulerStopped()   { r.record("scheduler stopped") }
+
+func (r *recorder) JobIntervalUpdated(job *Job, interval uint64) {
+	r.record("updated %s to %d", job.Name(), interval)
+}
+
+func (r *recorder) RunSucceeded(job *Job, d time.Duration) {
+	r.record("succeeded %s", job.Name())
+}
+
+func (r *recorder) RunFailed(job *Job, d time.Duration, err error) {
+	r.record("failed %s: %v", job.Name(), err)
+	r.s.PauseWithName(job.Name())
+}
+
+// failingStore is a `JobStore` whose every call fails
+type failingStore struct{}
+
+func (failingStore) Load(name string) (JobState, bool, error) {
+	return JobState{}, false, errors.New("load " + name)
+}
+func (failingStore) Save(state JobState) error { return errors.New("save " + state.Name) }
+func (failingStore) Delete(name string) error  { return errors.New("delete " + name) }
+
+// storeFailures records the failures of a store
+type storeFailures struct {
+	NopListener
+	mutex    sync.Mutex
+	failures []string
+}
+
+func (f *storeFailures) StoreFailed(job *Job, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failures = append(f.failures, job.Name()+": "+err.Error())
+}
+
+func TestStore(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Store")
+
+	dir, err := os.MkdirTemp("", "gocron")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s.Assert("`FileStore` saves, loads and deletes the state of the jobs", func(log sugar.Log) bool {
+		path := filepath.Join(dir, "roundtrip.json")
+		store, err := NewFileStore(path)
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		last := time.Date(2016, time.January, 6, 10, 0, 0, 0, time.UTC)
+		store.Save(JobState{Name: "b", LastRun: last, NextRun: last.Add(time.Hour)})
+		store.Save(JobState{Name: "a", LastRun: last, NextRun: last.Add(time.Minute)})
+		store.Delete("b")
+		store.Delete("c")
+
+		store, err = NewFileStore(path)
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		a, okA, _ := store.Load("a")
+		_, okB, _ := store.Load("b")
+
+		// no temporary file is left behind
+		files, _ := filepath.Glob(filepath.Join(dir, "roundtrip.json*"))
+		log("%v %v %v", a, okB, files)
+		return okA && !okB && a.NextRun.Equal(last.Add(time.Minute)) && len(files) == 1
+	})
+
+	s.Assert("`FileStore` writes the states saved at the same time at once", func(log sugar.Log) bool {
+		path := filepath.Join(dir, "batch.json")
+		store, _ := NewFileStore(path)
+		s, _ := newFakeScheduler()
+		s.store = store
+
+		store.begin()
+		s.EveryWithName(1, "a").Second().Do(func() {})
+		s.EveryWithName(1, "b").Second().Do(func() {})
+		s.EveryWithName(1, "c").Second().Do(func() {})
+		s.RemoveWithName("c")
+		_, err := os.Stat(path)
+		if !os.IsNotExist(err) || store.commit() != nil {
+			log("the file was written before the batch was committed: %v", err)
+			return false
+		}
+
+		// the jobs are initialized at once by the first call
+		s.RunPending()
+		reopened, _ := NewFileStore(path)
+		a, okA, _ := reopened.Load("a")
+		_, okB, _ := reopened.Load("b")
+		_, okC, _ := reopened.Load("c")
+		log("%v %v %v", a, okB, okC)
+		return okA && okB && !okC && !a.NextRun.IsZero()
+	})
+
+	s.Assert("`NewFileStore(...)` fails on a corrupt file", func(log sugar.Log) bool {
+		path := filepath.Join(dir, "corrupt.json")
+		os.WriteFile(path, []byte("{"), 0644)
+		_, err := NewFileStore(path)
+		return err != nil
+	})
+
+	s.Assert("a restarted scheduler resumes the named jobs where they left off", func(log sugar.Log) bool {
+		store, _ := NewFileStore(filepath.Join(dir, "restart.json"))
+		c := newCounter()
+
+		s, clock := newFakeScheduler()
+		s.store = store
+		s.EveryWithName(10, "job").Seconds().Do(c.task, "job")
+		s.Every(10).Seconds().Do(c.task, "unnamed")
+		s.Start()
+		tick(s, clock, 10)
+		s.Stop()
+
+		// the process is down while the run at 20 seconds is due
+		clock.Advance(15 * time.Second)
+		restarted := NewScheduler(WithClock(clock), WithStore(store)).(*scheduler)
+		restarted.EveryWithName(10, "job").Seconds().Do(c.task, "job")
+		restarted.Start()
+		defer restarted.Stop()
+		clock.BlockUntil(1)
+		restarted.running.Wait()
+		missed := c.count("job")
+
+		// and runs at 30 seconds on schedule
+		tick(restarted, clock, 5)
+
+		state, _, _ := store.Load("job")
+		_, unnamed, _ := store.Load("")
+		log("%v %v", c.runs, state)
+		return missed == 2 && c.count("job") == 3 && !unnamed &&
+			state.LastRun.Equal(clock.Now()) && state.NextRun.Equal(clock.Now().Add(10*time.Second))
+	})
+
+	s.Assert("a restarted scheduler computes the next run of the jobs whose schedule or location changed", func(log sugar.Log) bool {
+		store, _ := NewFileStore(filepath.Join(dir, "changed.json"))
+		last := time.Date(2016, time.January, 6, 10, 0, 0, 0, time.Local)
+		for _, name := range []string{"kept", "changed", "moved"} {
+			store.Save(JobState{Name: name, Schedule: "every 1 days at 10:00", Location: time.Local.String(), LastRun: last, NextRun: last.AddDate(0, 0, 1)})
+		}
+
+		s, _ := newFakeScheduler()
+		s.store = store
+		kept := s.EveryWithName(1, "kept").Day().At("10:00").Do(func() {})
+		changed := s.EveryWithName(1, "changed").Day().At("14:00").Do(func() {})
+		west := time.FixedZone("UTC-5", -5*60*60)
+		moved := s.EveryWithName(1, "moved").Day().At("10:00").Location(west).Do(func() {})
+		s.RunPending()
+
+		log("kept %v, changed %v, moved %v", kept.nextRun, changed.nextRun, moved.nextRun)
+		return kept.nextRun.Equal(last.AddDate(0, 0, 1)) && changed.nextRun.Equal(last.Add(4*time.Hour)) &&
+			moved.nextRun.In(west).Hour() == 10 && moved.nextRun.Before(last.AddDate(0, 0, 1))
+	})
+
+	s.Assert("removed jobs are deleted from the store", func(log sugar.Log) bool {
+		store, _ := NewFileStore(filepath.Join(dir, "remove.json"))
+		s := NewScheduler(WithStore(store)).(*scheduler)
+		s.EveryWithName(1, "a").Second().Do(func() {})
+		s.EveryWithName(1, "b").Second().Do(func() {})
+		s.RunPending()
+		_, saved, _ := store.Load("a")
+
+		s.RemoveWithName("a")
+		_, a, _ := store.Load("a")
+		_, b, _ := store.Load("b")
+		s.Clear()
+		_, cleared, _ := store.Load("b")
+		return saved && !a && b && !cleared
+	})
+
+	s.Assert("the state of the jobs running registered tasks keeps their definition", func(log sugar.Log) bool {
+		store, _ := NewFileStore(filepath.Join(dir, "definition.json"))
+		s := NewScheduler(WithStore(store)).(*scheduler)
+		s.EveryWithName(1, "registered").Hour().Tag("audit").DoTask("registry-count", "stored")
+		s.EveryWithName(1, "plain").Hour().Do(func() {})
+		s.RunPending()
+
+		registered, _, _ := store.Load("registered")
+		plain, _, _ := store.Load("plain")
+		if registered.Definition == nil || plain.Definition != nil {
+			log("%v %v", registered.Definition, plain.Definition)
+			return false
+		}
+		job, err := NewScheduler().AddDefinition(*registered.Definition)
+		return err == nil && job.Name() == "registered" && reflect.DeepEqual(job.Tags(), []string{"audit"})
+	})
+
+	s.Assert("the state of the jobs describes their schedule", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		specs := map[*Job]string{
+			s.Every(10).Seconds():                                       "every 10 seconds",
+			s.Every(1).Day().At("10:30", "18:00:15"):                    "every 1 days at 10:30,18:00:15",
+			s.Every(2).Monday().Friday().At("9:00"):                     "every 2 weeks on monday,friday at 9:00",
+			s.Every(1).Month().DayOfMonth(-3):                           "every 1 months on day -3",
+			s.Every(1).Month().LastDayOfMonth():                         "every 1 months on last day",
+			s.Every(1).Quarter().NthWeekday(2, time.Tuesday).At("9:00"): "every 1 quarters on weekday 2 tuesday at 9:00",
+			s.Cron("*/5 * * * *"):                                       "cron */5 * * * *",
+			s.Every(1).Schedule(s.Every(1).Hour().resolveSchedule()):    "custom",
+		}
+		for job, spec := range specs {
+			if job.spec() != spec {
+				log("expected %q, got %q", spec, job.spec())
+				return false
+			}
+		}
+		return true
+	})
+
+	s.Assert("store failures are reported to the listeners", func(log sugar.Log) bool {
+		s := NewScheduler(WithStore(failingStore{})).(*scheduler)
+		f := &storeFailures{}
+		s.AddListener(f)
+		s.EveryWithName(1, "a").Second().Do(func() {})
+		s.RunPending()
+		s.RemoveWithName("a")
+
+		failures := func() string {
+			f.mutex.Lock()
+			defer f.mutex.Unlock()
+			return fmt.Sprint(f.failures)
+		}
+		eventually(func() bool { return failures() == "[a: load a a: save a a: delete a]" })
+		log("%v", failures())
+		return failures() == "[a: load a a: save a a: delete a]"
+	})
+}
+
+func TestSpec(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Schedule specs")
+
+	s.Assert("`Spec(...)` parses the specs the jobs are described with", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		specs := []string{
+			"every 10 seconds",
+			"every 1 days at 10:30,18:00:15",
+			"every 2 weeks on monday,friday at 9:00",
+			"every 1 months on day -3",
+			"every 1 months on day 15 at 2:00",
+			"every 1 months on last day",
+			"every 1 quarters on weekday 2 tuesday at 9:00",
+			"every 1 quarters on weekday -1 friday",
+			"cron */5 * * * *",
+			"cron @daily",
+		}
+		for _, spec := range specs {
+			job := s.Every(1).Spec(spec)
+			if job.Err() != nil || job.spec() != spec {
+				log("%q: got %q, %v", spec, job.spec(), job.Err())
+				return false
+			}
+		}
+		return true
+	})
+
+	s.Assert("`Spec(...)` accepts singular units, short day names and spaces between times", func(log sugar.Log) bool {
+		job := Every(1).Spec("Every 1 week on Mon, wed at 9:00, 5:30pm")
+		log("%q %v", job.spec(), job.Err())
+		return job.Err() == nil && job.spec() == "every 1 weeks on monday,wednesday at 9:00,17:30"
+	})
+
+	s.Assert("`Spec(...)` records an error for invalid specs", func(log sugar.Log) bool {
+		specs := map[string]error{
+			"":                            ErrSpecNotValid,
+			"every day":                   ErrSpecNotValid,
+			"every 0 days":                ErrSpecNotValid,
+			"every 1 fortnights":          ErrSpecNotValid,
+			"every 1 hours at 10:00":      ErrSpecNotValid,
+			"every 1 days on monday":      ErrSpecNotValid,
+			"every 1 weeks on funday":     ErrSpecNotValid,
+			"every 1 months on day x":     ErrSpecNotValid,
+			"every 1 months on the 1st":   ErrSpecNotValid,
+			"every 1 days at 10:00 at 11": ErrSpecNotValid,
+			"every 1 days at 25:00":       ErrIncorrectTimeFormat,
+			"every 1 months on day 32":    ErrDayOfMonthNotValid,
+			"cron * * *":                  ErrCronExpressionNotValid,
+			"custom":                      ErrSpecNotValid,
+		}
+		for spec, expected := range specs {
+			if err := Every(1).Spec(spec).Err(); !errors.Is(err, expected) {
+				log("%q: expected %v, got %v", spec, expected, err)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// registered records the runs of the tasks registered by `TestMain`
+var registered = struct {
+	*counter
+	mutex sync.Mutex
+	sums  []int
+}{counter: newCounter()}
+
+// the registry is global and can't be reset, so the tasks are registered once,
+// and each run of the tests resets their records instead
+func TestMain(m *testing.M) {
+	RegisterTask("registry-count", registered.task)
+	RegisterTask("registry-sum", func(ctx context.Context, values []int, offset int) {
+		registered.mutex.Lock()
+		defer registered.mutex.Unlock()
+		sum := offset
+		for _, v := range values {
+			sum += v
+		}
+		registered.sums = append(registered.sums, sum)
+	})
+	os.Exit(m.Run())
+}
+
+func TestRegistry(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Task registry")
+
+	c := registered.counter
+	c.mutex.Lock()
+	c.runs = make(map[string]int)
+	c.mutex.Unlock()
+	mutex := &registered.mutex
+	mutex.Lock()
+	registered.sums = nil
+	mutex.Unlock()
+
+	s.Assert("`RegisterTask(...)` rejects non funcs and registered names", func(log sugar.Log) bool {
+		return errors.Is(RegisterTask("registry-int", 1), ErrTaskIsNotAFuncError) &&
+			errors.Is(RegisterTask("registry-count", task), ErrTaskAlreadyRegistered)
+	})
+
+	s.Assert("`DoTask(...)` runs the registered task with its arguments converted through JSON", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		s.Every(1).Second().DoTask("registry-sum", []float64{1, 2}, 3.0)
+		s.RunAll()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		log("%v", registered.sums)
+		return len(registered.sums) == 1 && registered.sums[0] == 6
+	})
+
+	s.Assert("`DoTask(...)` records an error for unknown tasks and mismatched arguments", func(log sugar.Log) bool {
+		return errors.Is(newJob(1).Second().DoTask("registry-unknown").Err(), ErrTaskNotRegistered) &&
+			errors.Is(newJob(1).Second().DoTask("registry-sum", []int{1}).Err(), ErrMissmatchedTaskParams) &&
+			errors.Is(newJob(1).Second().DoTask("registry-sum", "1", 2).Err(), ErrMissmatchedTaskParams) &&
+			newJob(1).Second().DoTask("registry-count", func() {}).Err() != nil
+	})
+
+	s.Assert("job definitions round-trip through JSON", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		taipei, _ := time.LoadLocation("Asia/Taipei")
+		job := s.EveryWithName(2, "report").Weekday(time.Monday).At("9:00").Location(taipei).Timeout(time.Minute).
+			DoTask("registry-count", "report").DoTask("registry-sum", []int{1}, 2)
+		s.PauseWithName("report")
+
+		def, err := job.Definition()
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		data, _ := json.Marshal(def)
+		log("%s", data)
+
+		var decoded JobDefinition
+		json.Unmarshal(data, &decoded)
+		restored, err := NewScheduler(WithClock(clock)).AddDefinition(decoded)
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		redef, _ := restored.Definition()
+		redata, _ := json.Marshal(redef)
+		return string(data) == string(redata) && restored.IsPaused() &&
+			string(data) == `{"name":"report","schedule":"every 2 weeks on monday at 9:00","location":"Asia/Taipei",`+
+				`"tasks":[{"name":"registry-count","args":["report"]},{"name":"registry-sum","args":[[1],2]}],`+
+				`"paused":true,"timeout":60000000000}`
+	})
+
+	s.Assert("`Tag(...)` labels the job, and the tags are kept in its definition", func(log sugar.Log) bool {
+		job := NewScheduler().EveryWithName(1, "tagged").Hour().Tag("nightly", "storage").Tag("nightly").
+			DoTask("registry-count", "tagged")
+		def, err := job.Definition()
+		log("%v %v", job.Tags(), def.Tags)
+		return err == nil && reflect.DeepEqual(job.Tags(), []string{"nightly", "storage"}) &&
+			reflect.DeepEqual(def.Tags, job.Tags())
+	})
+
+	s.Assert("`AddDefinition(...)` replaces the job with the same name and runs it", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		s.EveryWithName(1, "defined").Second().Do(c.task, "replaced")
+		s.Start()
+		defer s.Stop()
+
+		_, err := s.AddDefinition(JobDefinition{
+			Name:     "defined",
+			Schedule: "every 2 seconds",
+			Tasks:    []TaskDefinition{{Name: "registry-count", Args: []json.RawMessage{[]byte(`"defined"`)}}},
+		})
+		tick(s, clock, 4)
+		log("%v %v", err, c.runs)
+		return err == nil && s.Len() == 1 && c.count("defined") == 2 && c.count("replaced") == 0
+	})
+
+	s.Assert("`AddDefinition(...)` returns the error of invalid definitions", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		s.EveryWithName(1, "kept").Second().Do(func() {})
+		defs := map[string]JobDefinition{
+			"spec":     {Name: "kept", Schedule: "every 1 eons", Tasks: []TaskDefinition{{Name: "registry-count", Args: []json.RawMessage{[]byte(`"x"`)}}}},
+			"location": {Name: "kept", Schedule: "every 1 seconds", Location: "Mars/Olympus"},
+			"task":     {Name: "kept", Schedule: "every 1 seconds", Tasks: []TaskDefinition{{Name: "registry-unknown"}}},
+			"no task":  {Name: "kept", Schedule: "every 1 seconds"},
+		}
+		for name, def := range defs {
+			if job, err := s.AddDefinition(def); err == nil || job != nil {
+				log("%s: expected an error", name)
+				return false
+			}
+		}
+		_, err := s.EveryWithName(1, "plain").Second().Do(func() {}).Definition()
+		return s.Len() == 2 && errors.Is(err, ErrTaskNotRegistered)
+	})
+
+	s.Assert("`UpdateDefinition(...)` changes the job in place, keeping its last run", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		job := s.EveryWithName(3, "updated").Seconds().Tag("old").DoTask("registry-count", "before")
+		s.Start()
+		defer s.Stop()
+		tick(s, clock, 4)
+
+		updated, err := s.UpdateDefinition(JobDefinition{
+			Name:     "updated",
+			Schedule: "every 5 seconds",
+			Tasks:    []TaskDefinition{{Name: "registry-count", Args: []json.RawMessage{[]byte(`"after"`)}}},
+			Tags:     []string{"new"},
+		})
+		s.mutex.Lock()
+		lastRun, nextRun := job.lastRun, job.nextRun
+		s.mutex.Unlock()
+		// the next run follows the new schedule from the run a second ago
+		tick(s, clock, 4)
+		log("%v %v %v %v", err, lastRun, nextRun, c.runs)
+		return err == nil && updated == job && s.Len() == 1 && nextRun.Equal(lastRun.Add(5*time.Second)) &&
+			c.count("before") == 1 && c.count("after") == 1 && reflect.DeepEqual(job.Tags(), []string{"new"})
+	})
+
+	s.Assert("`UpdateDefinition(...)` adds the missing jobs and rejects invalid definitions", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		job := s.EveryWithName(1, "kept").Second().DoTask("registry-count", "kept")
+		_, invalid := s.UpdateDefinition(JobDefinition{Name: "kept", Schedule: "every 1 eons"})
+		added, err := s.UpdateDefinition(JobDefinition{
+			Name:     "added",
+			Schedule: "every 1 seconds",
+			Tasks:    []TaskDefinition{{Name: "registry-count", Args: []json.RawMessage{[]byte(`"added"`)}}},
+		})
+		def, _ := job.Definition()
+		return invalid != nil && def.Schedule == "every 1 seconds" && err == nil && added.Name() == "added" && s.Len() == 2
+	})
+}
+
+func TestListener(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Listener")
+
+	s.Assert("listeners are notified of the lifecycle of the jobs", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		r := &recorder{s: s}
+		s.AddListener(r)
+
+		s.EveryWithName(1, "a").Second().Do(func() {})
+		s.EveryWithName(1, "b").Second().Do(func() error { return errors.New("oops") })
+		s.UpdateIntervalWithName("a", 2)
+		s.RemoveWithName("a")
+		s.ResumeAll()
+
+		now := time.Now()
+		s.runPending(now)
+		s.runPending(now.Add(time.Second)).Wait()
+
+		expected := "[added a added b updated a to 2 removed a resumed b started b failed b: oops paused b]"
+		log("events %s", r.wait(8))
+		return r.recorded() == expected
+	})
+
+	s.Assert("listeners are notified of the skipped runs", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		r := &recorder{s: s}
+		s.AddListener(r)
+
+		release := make(chan bool)
+		s.EveryWithName(1, "slow").Second().Overlap(OverlapSkip).Do(func() { <-release })
+		s.EveryWithName(1, "late").Second().Misfire(MisfireSkip, 0).Do(func() {})
+
+		now := time.Now()
+		s.runPending(now)
+		runs := s.runPending(now.Add(time.Second))
+		s.runPending(now.Add(10 * time.Second))
+		close(release)
+		runs.Wait()
+
+		// added, started, skipped and succeeded slow and late
+		log("events %s", r.wait(8))
+		return strings.Count(r.recorded(), "skipped slow") == 1 &&
+			strings.Count(r.recorded(), "skipped late") == 1
+	})
+
+	s.Assert("listeners are notified when the scheduler starts and stops", func(log sugar.Log) bool {
+		s := NewScheduler()
+		r := &recorder{s: s}
+		s.AddListener(r)
+
+		s.Start()
+		s.Stop()
+
+		log("events %s", r.wait(2))
+		return r.recorded() == "[scheduler started scheduler stopped]"
+	})
+
+	s.Assert("listeners can stop the scheduler from the events of the run loop", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		l := &stopper{s: s, stopped: make(chan bool, 1)}
+		s.AddListener(l)
+
+		s.Every(1).Second().Overlap(OverlapSkip).Do(func(ctx context.Context) { <-ctx.Done() })
+		s.Start()
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+
+		select {
+		case <-l.stopped:
+			return !s.IsRunning()
+		case <-time.After(5 * time.Second):
+			log("`Stop()` didn't return")
+			return false
+		}
+	})
+}
+
+// stopper is a listener stopping the scheduler when a run is skipped
+type stopper struct {
+	NopListener
+	s       Scheduler
+	stopped chan bool
+}
+
+func (l *stopper) RunSkipped(job *Job) {
+	l.s.Stop()
+	l.stopped <- true
+}
+
+func TestQueue(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Run queue")
+
+	s.Assert("jobs are queued by their next run, then by interval and the order they were added in", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		now := time.Now()
+		var jobs []*Job
+		for _, interval := range []uint64{5, 3, 9, 1, 3, 7} {
+			jobs = append(jobs, s.Every(interval).Seconds().Do(func() {}))
+		}
+		s.runPending(now)
+
+		s.Remove(jobs[2])
+		s.RemoveWithName("missing")
+		jobs[0].nextRun = now.Add(2 * time.Second)
+		s.jobs.update(jobs[0])
+
+		var order []*Job
+		for s.jobs.Len() > 0 {
+			order = append(order, heap.Pop(&s.jobs).(*Job))
+		}
+		expected := []*Job{jobs[3], jobs[0], jobs[1], jobs[4], jobs[5]}
+		if len(order) != len(expected) {
+			log("unexpected %d jobs", len(order))
+			return false
+		}
+		for i := range expected {
+			if order[i] != expected[i] {
+				log("job %d: expected interval %d, got %d", i, expected[i].interval, order[i].interval)
+				return false
+			}
+		}
+		return true
+	})
+
+	s.Assert("jobs built with an invalid argument are kept at the bottom of the queue", func(log sugar.Log) bool {
+		s := NewScheduler().(*scheduler)
+		invalid := s.Every(1).Day().At("25:00").Do(func() {})
+		valid := s.Every(1).Second().Do(func() {})
+
+		now := time.Now()
+		s.runPending(now)
+		job, next := s.NextRun()
+		return job == valid && next.Equal(now.Add(time.Second)) && s.Jobs()[1] == invalid
+	})
+}
+
+// newBenchmarkScheduler returns a scheduler with n initialized jobs, due over the next hour
+func newBenchmarkScheduler(n int, now time.Time) *scheduler {
+	s := NewScheduler().(*scheduler)
+	for i := 0; i < n; i++ {
+		s.EveryWithName(uint64(i%3600+1), fmt.Sprint("job", i)).Seconds().Do(func() {})
+	}
+	s.runPending(now)
+	return s
+}
+
+func benchmarkRunPending(b *testing.B, n int) {
+	now := time.Now()
+	s := newBenchmarkScheduler(n, now)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// a tick with no job due
+		s.runPending(now)
+	}
+}
+
+func benchmarkEveryWithName(b *testing.B, n int) {
+	now := time.Now()
+	s := newBenchmarkScheduler(n, now)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// replace a job, removing and adding it
+		s.EveryWithName(1, fmt.Sprint("job", i%n)).Seconds().Do(func() {})
+	}
+}
+
+func benchmarkReschedule(b *testing.B, n int) {
+	now := time.Now()
+	s := newBenchmarkScheduler(n, now)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// move the next due job to its next run
+		job := s.jobs[0]
+		job.advance()
+		s.jobs.update(job)
+	}
+}
+
+func BenchmarkRunPending10k(b *testing.B)     { benchmarkRunPending(b, 10000) }
+func BenchmarkRunPending100k(b *testing.B)    { benchmarkRunPending(b, 100000) }
+func BenchmarkEveryWithName10k(b *testing.B)  { benchmarkEveryWithName(b, 10000) }
+func BenchmarkEveryWithName100k(b *testing.B) { benchmarkEveryWithName(b, 100000) }
+func BenchmarkReschedule10k(b *testing.B)     { benchmarkReschedule(b, 10000) }
+func BenchmarkReschedule100k(b *testing.B)    { benchmarkReschedule(b, 100000) }
+
+// every is a schedule running a job at a fixed sub-second interval
+type every time.Duration
+
+func (d every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(d))
+}
+
+// until runs on every second until `last`, then has no more runs. It counts the calls to `Next`
+type until struct {
+	last  time.Time
+	calls *int64
+}
+
+func (u until) Next(after time.Time) time.Time {
+	atomic.AddInt64(u.calls, 1)
+	if next := after.Truncate(time.Second).Add(time.Second); !next.After(u.last) {
+		return next
+	}
+	return time.Time{}
+}
+
+// lagRecorder is a listener recording the lag of the runs on the clock of the scheduler
+type lagRecorder struct {
+	NopListener
+	clock Clock
+	lags  chan time.Duration
+}
+
+func (r *lagRecorder) RunStarted(job *Job, scheduled time.Time) {
+	r.lags <- r.clock.Now().Sub(scheduled)
+}
+
+func TestLoop(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Run loop")
+
+	s.Assert("the loop wakes up when the next job is due", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		r := &lagRecorder{clock: clock, lags: make(chan time.Duration, 10)}
+		s.AddListener(r)
+		s.Start()
+		defer s.Stop()
+
+		// jobs added after the start are run too
+		s.Every(1).Schedule(every(30 * time.Millisecond)).Do(func() {})
+		for i := 0; i < 5; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(30 * time.Millisecond)
+			lag := <-r.lags
+			log("run %d started %v late", i, lag)
+			if lag != 0 {
+				return false
+			}
+		}
+		return true
+	})
+
+	s.Assert("the loop re-arms when the next job is removed", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		s.Start()
+		defer s.Stop()
+
+		ran := make(chan string, 10)
+		slow := s.Every(1).Schedule(every(20 * time.Millisecond)).Do(func() { ran <- "removed" })
+		s.Remove(slow)
+		s.Every(1).Schedule(every(50 * time.Millisecond)).Do(func() { ran <- "kept" })
+
+		clock.BlockUntil(1)
+		clock.Advance(20 * time.Millisecond)
+		clock.BlockUntil(1)
+		s.running.Wait()
+		select {
+		case job := <-ran:
+			log("%s job ran before the kept job was due", job)
+			return false
+		default:
+		}
+		clock.Advance(30 * time.Millisecond)
+		select {
+		case job := <-ran:
+			return job == "kept"
+		case <-time.After(5 * time.Second):
+			log("the job didn't run")
+			return false
+		}
+	})
+
+	s.Assert("jobs whose schedule has no more runs never run again", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		c := newCounter()
+		var calls int64
+		job := s.Every(1).Schedule(until{last: clock.Now().Add(2 * time.Second), calls: &calls}).Do(c.task, "until")
+		// the first call initializes the job
+		s.RunPending()
+		for i := 0; i < 5; i++ {
+			clock.Advance(time.Second)
+			s.RunPending()
+		}
+
+		_, next := s.NextRun()
+		s.mutex.Lock()
+		_, wakeup := s.nextWakeup()
+		s.mutex.Unlock()
+		log("%d runs, %d calls to Next", c.count("until"), atomic.LoadInt64(&calls))
+		return c.count("until") == 2 && atomic.LoadInt64(&calls) == 3 && next.IsZero() && !wakeup && job.Err() == nil
+	})
+
+	s.Assert("`Stop()` doesn't wait for the loop to be idle", func(log sugar.Log) bool {
+		s, clock := newFakeScheduler()
+		s.Every(1).Schedule(every(time.Millisecond)).Do(func() {})
+		for i := 0; i < 20; i++ {
+			s.Start()
+			clock.BlockUntil(1)
+			clock.Advance(time.Millisecond)
+			s.Stop()
+		}
+		return !s.IsRunning()
+	})
 }
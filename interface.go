@@ -1,13 +1,29 @@
 package gocron
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Scheduler keeps a slice of jobs that it executes at a regular interval
 type Scheduler interface {
 
+	// AddDefinition creates a job from its definition, and adds it to the `Scheduler` and job Map.
+	// It returns an error if the definition isn't valid
+	AddDefinition(JobDefinition) (*Job, error)
+
+	// AddListener registers a listener notified of the lifecycle of the scheduler and its jobs
+	AddListener(Listener)
+
 	// Clear removes all of the jobs that have been added to the scheduler
 	Clear()
 
+	// Clock returns the clock the scheduler reads the time from, see `WithClock`
+	Clock() Clock
+
+	// Cron creates a new job from a standard cron expression, and adds it to the `Scheduler`
+	Cron(expr string) *Job
+
 	// Emergency create a emergency job, and adds it to the `Scheduler`
 	Emergency() *Job
 
@@ -17,6 +33,9 @@ type Scheduler interface {
 	// EveryWithName creates a new job, and adds it to the `Scheduler` and job Map
 	EveryWithName(interval uint64, name string) *Job
 
+	// Jobs returns the jobs that have been added to the scheduler, sorted by their next run
+	Jobs() []*Job
+
 	// IsRunning returns true if the job  has started
 	IsRunning() bool
 
@@ -25,19 +44,24 @@ type Scheduler interface {
 	Location(*time.Location)
 
 	// NextRun returns the next next job to be run and the time in which
-	// it will be run
+	// it will be run, or the zero time if none of the jobs will run again
 	NextRun() (*Job, time.Time)
 
-	// Remove removes an individual job from the scheduler. It returns true
-	// if the job was found and removed from the `Scheduler`
+	// Remove removes an individual job from the scheduler, and cancels its in-progress runs.
+	// It returns true if the job was found and removed from the `Scheduler`
 	Remove(*Job) bool
 
+	// UpdateDefinition changes the job with the same name as the definition in place, keeping its
+	// last run and its in-progress runs. It returns an error if the definition isn't valid
+	UpdateDefinition(JobDefinition) (*Job, error)
+
 	// UpdateIntervalWithName update an individual job's interval from the scheduler by name.
-	// It returns true if the job was found and update interval
-	UpdateIntervalWithName(name string, interval uint64) bool
+	// It returns an error, and leaves the job unchanged, if no job has this name, the interval
+	// is 0, or the schedule of the job has no interval, e.g. a cron expression
+	UpdateIntervalWithName(name string, interval uint64) error
 
-	// RemoveWithName removes an individual job from the scheduler by name. It returns true
-	// if the job was found and removed from the `Scheduler`
+	// RemoveWithName removes an individual job from the scheduler by name, and cancels its
+	// in-progress runs. It returns true if the job was found and removed from the `Scheduler`
 	RemoveWithName(string) bool
 
 	// PauseWithName pause an individual job by name. It returns true if the job was found and set enabled
@@ -66,6 +90,10 @@ type Scheduler interface {
 	// Start starts the scheduler
 	Start()
 
-	// Stop stops the scheduler from executing jobs
+	// Stop stops the scheduler from executing jobs, and cancels the context of the in-progress runs
 	Stop()
+
+	// Shutdown stops the scheduler from executing jobs, and waits for the in-progress runs to finish
+	// until `ctx` is done. It returns the jobs that were still running and the error of `ctx` then
+	Shutdown(ctx context.Context) ([]*Job, error)
 }
@@ -0,0 +1,40 @@
+// Package sugar is the assertion helper the tests of gocron are written with.
+// It follows the API of github.com/takawang/sugar, which can't be fetched as a
+// module, so the tests build in module mode without it
+package sugar
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Log records a formatted message, reported if the assertion fails
+type Log func(format string, args ...interface{})
+
+// Sugar runs the assertions of a test
+type Sugar struct {
+	t *testing.T
+}
+
+// New creates the assertion helper of a test
+func New(t *testing.T) *Sugar {
+	return &Sugar{t: t}
+}
+
+// Title logs the title of the following assertions
+func (s *Sugar) Title(title string) {
+	s.t.Helper()
+	s.t.Logf("== %s", title)
+}
+
+// Assert runs the assertion, and fails the test with the messages it logged if it returns false
+func (s *Sugar) Assert(description string, assertion func(log Log) bool) {
+	s.t.Helper()
+	var logs []string
+	log := func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	}
+	if !assertion(log) {
+		s.t.Errorf("FAIL: %s\n%v", description, logs)
+	}
+}
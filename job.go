@@ -1,7 +1,13 @@
 package gocron
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,26 +17,43 @@ const (
 
 	// Week is the duration for a Weeks worth of time
 	Week = 7 * Day
+
+	// monthly is the unit of monthly jobs. Months have no fixed duration,
+	// so it only marks the job as monthly
+	monthly time.Duration = -1
+
+	// quarterly is the unit of quarterly jobs, see `monthly`
+	quarterly time.Duration = -3
 )
 
 // Job calculates the time intervals in which a task should be executed.
+// The methods building a job lock the scheduler it was added to, so jobs can be
+// built and changed while the scheduler is running them
 type Job struct {
 
 	// pause interval * unit bettween runs
 	interval uint64
 
-	// the tasks this job executes
+	// the tasks this job executes. The tasks, their parameters and `tasksContext` are
+	// written holding both the scheduler and the run state locks, so they can be read holding either
 	tasks []reflect.Value
 
 	// the parameters that will be passed to this job upon execution
 	tasksParams [][]reflect.Value
 
+	// whether each task takes the context of the run as its first parameter
+	tasksContext []bool
+
+	// the registered name and the arguments of each task, see `DoTask`.
+	// The name is empty for the tasks passed to `Do`
+	tasksDef []TaskDefinition
+
 	// time units the `interval` is the quantity of ,
 	// e.g. `time.Minute`, `time.Hour`, `Week`...
 	unit time.Duration
 
-	// optional time at which this job runs
-	atTime time.Duration
+	// optional times of day at which this job runs
+	atTimes []time.Duration
 
 	// time of last run
 	lastRun time.Time
@@ -38,42 +61,211 @@ type Job struct {
 	// time of next run
 	nextRun time.Time
 
-	// specific day of the week to start on
-	weekDay time.Weekday
+	// specific days of the week to run on. For monthly and quarterly jobs,
+	// the single day of the week of `nthWeekday`
+	weekDays []time.Weekday
+
+	// specific day of the month to run on, counted from the end of the month
+	// when negative. 0 means the day of the month the job was initialized
+	monthDay int
+
+	// nth `weekDays` of the month or quarter to run on, counted from the end
+	// of the month or quarter when negative. 0 means `monthDay` is used instead
+	nthWeekday int
 
 	// location the time of the job takes place in
 	location *time.Location
 
-	// should run this job flag
+	// should run this job flag. It is written holding both the scheduler
+	// and the run state locks, so it can be read holding either
 	enabled bool
+
+	// name of the job in the scheduler's job map, empty for jobs created with `Every`
+	name string
+
+	// the first error recorded while building the job. Jobs with an error never run
+	err error
+
+	// the in-progress runs of the job
+	state runState
+
+	// schedule set explicitly by `Schedule` or `Scheduler.Cron`. When nil, a
+	// built-in schedule is derived from `interval`, `unit`, `weekDays` and `atTimes`
+	schedule Schedule
+
+	// the schedule `nextRun` is computed from, resolved by `init`
+	resolved Schedule
+
+	// the scheduler the job was added to, nil for jobs that weren't
+	scheduler *scheduler
+
+	// position of the job in the queue of its scheduler, -1 when it isn't queued
+	index int
+
+	// order the job was added to its scheduler in, orders the jobs with the same next run
+	seq uint64
+
+	// what happens to the runs the scheduler missed by more than `misfireThreshold`
+	misfire          MisfirePolicy
+	misfireThreshold time.Duration
+
+	// labels of the job, see `Tag`
+	tags []string
+
+	// state loaded from the store of the scheduler, restored by the first `init`
+	restored *JobState
 }
 
+var (
+	// contextType is the type of the `context.Context` tasks can take as their first parameter
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+	// errorType is the type of the error tasks can return as their last return value
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
 // NewJob creates a new job
 func newJob(interval uint64) *Job {
-	if interval == 0 {
-		panic(ErrIntervalNotValid)
-	}
-	return &Job{
+	j := &Job{
 		interval: interval,
 		location: time.Local,
-		atTime:   -time.Second,
 		enabled:  true,
+		index:    -1,
+	}
+	if interval == 0 {
+		j.setError("Every", interval, ErrIntervalNotValid)
+	}
+	return j
+}
+
+// lock locks the scheduler the job was added to, so the scheduler doesn't read
+// the job while it is being built. It returns the function unlocking it
+//
+// Example
+//
+//  // ...
+//	defer j.lock()()
+//
+func (j *Job) lock() func() {
+	if s := j.scheduler; s != nil {
+		s.mutex.Lock()
+		return s.mutex.Unlock
+	}
+	return func() {}
+}
+
+// setName sets the name of the job, including in the error recorded so far
+func (j *Job) setName(name string) {
+	j.name = name
+	if err, ok := j.err.(*JobError); ok {
+		err.Job = name
+	}
+}
+
+// setError records the first invalid argument passed to the job
+func (j *Job) setError(op string, arg interface{}, err error) {
+	if j.err == nil {
+		j.err = &JobError{Job: j.name, Op: op, Arg: arg, Err: err}
 	}
 }
 
+// recordError records the first invalid argument passed to the job,
+// locking the scheduler the job was added to
+func (j *Job) recordError(op string, arg interface{}, err error) {
+	defer j.lock()()
+
+	j.setError(op, arg, err)
+}
+
+// Err returns the first error recorded while building the job, or nil.
+// A job with an error is never run by the scheduler.
+// The error is a `*JobError` wrapping one of the package errors
+//
+// Example
+//
+//  // ...
+//	job := Every(1).Day().At(config.Time).Do(task)
+//	if err := job.Err(); err != nil {
+//		return err
+//	}
+//
+func (j *Job) Err() error {
+	defer j.lock()()
+
+	return j.err
+}
+
+// Tag adds labels to the job, e.g. to group the jobs of a config file.
+// Tags are kept in the `JobDefinition` of the job, and don't change its schedule
+//
+// Example
+//
+//  // ...
+//	s.EveryWithName(1, "backup").Day().At("02:00").Tag("nightly", "storage").Do(backup)
+//
+func (j *Job) Tag(tags ...string) *Job {
+	defer j.lock()()
+
+	for _, tag := range tags {
+		if !containsString(j.tags, tag) {
+			j.tags = append(j.tags, tag)
+		}
+	}
+	return j
+}
+
+// Tags returns the labels of the job added by `Tag`
+func (j *Job) Tags() []string {
+	defer j.lock()()
+
+	return append([]string(nil), j.tags...)
+}
+
+// containsString returns true if the slice contains the string
+func containsString(slice []string, s string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the name the job was created with by `EveryWithName`
+func (j *Job) Name() string {
+	return j.name
+}
+
 // pause disable the job
 func (j *Job) pause() {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
 	j.enabled = false
 }
 
 // resume re-enable the job
 func (j *Job) resume() {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
 	j.enabled = true
 }
 
+// IsPaused returns true if the job was paused by `PauseWithName` or `PauseAll`
+func (j *Job) IsPaused() bool {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	return !j.enabled
+}
+
 // updateInterval update interval
 func (j *Job) updateInterval(interval uint64) {
 	j.interval = interval
+	if j.resolved != nil {
+		j.resolved = j.resolveSchedule()
+	}
 }
 
 // should run returns true if the job should be run now
@@ -90,13 +282,80 @@ func (j *Job) shouldRun(now time.Time) bool {
 	return false
 }
 
-// run the job
+// run the job synchronously
 func (j *Job) run() {
+	j.advance()
+	_, err := j.exec(context.Background(), 0)
+	j.setLastError(err)
+}
+
+// advance moves the job to its next run. The scheduler calls it while holding
+// its lock, right before dispatching the tasks with `exec`
+func (j *Job) advance() {
 	j.lastRun = j.nextRun
-	for i, task := range j.tasks {
-		task.Call(j.tasksParams[i])
+	j.nextRun = j.next(j.lastRun)
+}
+
+// next returns the first run of the resolved schedule after `after`,
+// or `never` if the schedule has no more runs
+func (j *Job) next(after time.Time) time.Time {
+	next := j.resolved.Next(after)
+	if !next.After(after) {
+		return never
 	}
-	j.nextRun = j.lastRun.Add(time.Duration(j.interval) * j.unit)
+	return next
+}
+
+// exec calls the tasks of the job from the task `from`, until the run is cancelled or
+// a task fails. Tasks taking a `context.Context` are passed the context of the run.
+// It returns the error of the failed task, or one wrapping the error of the context
+// if the run was cancelled before all the tasks were called, along with the task
+// the run stopped at, which a retry resumes from
+func (j *Job) exec(ctx context.Context, from int) (int, error) {
+	// `Do` may add tasks while the job is running
+	j.state.mutex.Lock()
+	tasks, tasksParams, tasksContext := j.tasks, j.tasksParams, j.tasksContext
+	j.state.mutex.Unlock()
+
+	for i := from; i < len(tasks); i++ {
+		if err := ctx.Err(); err != nil {
+			return i, fmt.Errorf("%d of %d tasks were not called: %w", len(tasks)-i, len(tasks), err)
+		}
+		if err := call(ctx, tasks[i], tasksParams[i], tasksContext[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(tasks), nil
+}
+
+// call calls a task of a job with its parameters, preceded by the context of the run
+// if `withContext` is true. It returns a `*PanicError` if the task panics,
+// or the error the task returns if its last return value is an error
+func call(ctx context.Context, task reflect.Value, params []reflect.Value, withContext bool) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	if withContext {
+		params = append([]reflect.Value{reflect.ValueOf(ctx)}, params...)
+	}
+	results := task.Call(params)
+
+	if n := len(results); n > 0 && results[n-1].Type() == errorType && !results[n-1].IsNil() {
+		return results[n-1].Interface().(error)
+	}
+	return nil
+}
+
+// now returns the current time in the location of the job, according
+// to the clock of its scheduler if it was added to one
+func (j *Job) now() time.Time {
+	if j.scheduler != nil {
+		return j.scheduler.clock.Now().In(j.location)
+	}
+	return time.Now().In(j.location)
 }
 
 // isInit returns true if the the `lastRun` and `nextRun` time
@@ -105,96 +364,248 @@ func (j *Job) isInit() bool {
 	return !j.lastRun.IsZero() && !j.nextRun.IsZero()
 }
 
-// init sets the `lastRun` and `nextRun` times
+// init resolves the schedule of the job and sets the `lastRun` and `nextRun` times.
+// A job restored from a store resumes from its stored times instead, computing its
+// next run again from its last run if its schedule or location changed in the meantime
 func (j *Job) init(now time.Time) {
-	// compute the current time
-	currentTime := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	restored := j.restored
+	j.restored = nil
+	if restored != nil && !restored.LastRun.IsZero() {
+		// the defaults are derived from the time the job was initialized before the restart
+		now = restored.LastRun
+	}
+	now = now.In(j.location)
 
-	// set the default atTime of the job if it hasn't been set explicitly by `At`
-	if j.atTime < 0 {
-		j.atTime = currentTime
+	// set the default atTimes of the job if they haven't been set explicitly by `At`
+	if len(j.atTimes) == 0 {
+		j.atTimes = []time.Duration{time.Duration(now.Hour())*time.Hour +
+			time.Duration(now.Minute())*time.Minute +
+			time.Duration(now.Second())*time.Second}
 	}
 
-	// create the lastRun time
-	if j.unit == Week {
-		j.lastRun = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, now.Second(), now.Nanosecond(), j.location).
-			Add(time.Duration(j.weekDay-now.Weekday()) * Day).
-			Add(j.atTime)
+	// monthly and quarterly jobs default to the day of the month the job was initialized
+	if (j.unit == monthly || j.unit == quarterly) && j.monthDay == 0 {
+		j.monthDay = now.Day()
+	}
 
-		// the lastRun occured last week. This way, if a job is scheduled to occur weekly for tomorrow, it will run tomorrow.
-		if j.lastRun.After(now) {
-			j.lastRun.Add(-1 * Week)
-		}
+	j.resolved = j.resolveSchedule()
+	j.lastRun = now
+	j.nextRun = j.next(now)
+	if restored != nil && !restored.NextRun.IsZero() && restored.Schedule == j.spec() &&
+		restored.Location == j.location.String() {
+		j.nextRun = restored.NextRun.In(j.location)
+	}
+}
 
-	} else if j.unit == Day {
-		j.lastRun = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, now.Second(), now.Nanosecond(), j.location).
-			Add(j.atTime)
+// resolveSchedule returns the explicit schedule of the job, or the built-in
+// schedule matching its `unit`
+func (j *Job) resolveSchedule() Schedule {
+	if j.schedule != nil {
+		return j.schedule
+	}
 
-		// the lastRun occured yesterday. This way, if a job is scheduled to occur daily before the current time,
-		// then it will run today at that time
-		if j.lastRun.After(now) {
-			j.lastRun = j.lastRun.Add(-1 * Day)
+	switch j.unit {
+	case monthly, quarterly:
+		schedule := &monthlySchedule{
+			interval:   j.interval,
+			period:     int(j.unit / monthly),
+			monthDay:   j.monthDay,
+			nthWeekday: j.nthWeekday,
+			atTimes:    j.atTimes,
 		}
-
-	} else {
-		j.lastRun = now
+		if j.nthWeekday != 0 {
+			schedule.weekDay = j.weekDays[0]
+		}
+		return schedule
+	case Week:
+		return &weeklySchedule{interval: j.interval, weekDays: j.weekDays, atTimes: j.atTimes}
+	case Day:
+		return &dailySchedule{interval: j.interval, atTimes: j.atTimes}
+	default:
+		return &intervalSchedule{every: time.Duration(j.interval) * j.unit}
 	}
-
-	// create the nextRun
-	j.nextRun = j.lastRun.Add(time.Duration(j.interval) * j.unit)
 }
 
-// Do specifies the taks that should be called executed and the parameters it should be passed
+// Do specifies the taks that should be called executed and the parameters it should be passed.
+// It must be called last when building the job, since the scheduler starts running it right away.
+// If the first parameter of the task is a `context.Context` that isn't passed to `Do`,
+// the task is passed the context of the run. It is cancelled when the scheduler stops,
+// the job is removed, the run is replaced (see `OverlapReplace`) or its `Timeout` elapses.
+//
+// A run fails if one of its tasks panics, or returns a non-nil error as its last
+// return value. The remaining tasks of a failed run are not called, and the
+// error is reported by `LastError`.
+//
 // Example
 //
 //  // ...
 //	job := Every(1).Day().At("10:30").Do(task, paramOne, "paramTwo")  // performs `task(paramOne, "paramTwo")` every day at 10:30 am
 //  job.Do(task2, paramThree, "paramFour")                            // `task2(paramThree, "paramFour")` will perperformed at the same interval
+//  Every(1).Hour().Do(func(ctx context.Context, url string) { ... }, url) // performs the func with the context of the run and `url`
 //
 func (j *Job) Do(task interface{}, params ...interface{}) *Job {
-	// reflect the task and params in to values
+	// the scheduler waits for the first task before running the job
+	if j.addTask(task, params, TaskDefinition{}) && j.scheduler != nil {
+		j.scheduler.schedule(j)
+	}
+	return j
+}
+
+// addTask adds the task and its parameters to the job, with the definition of the task
+// if it is registered. It returns false, and records an error, if the task can't be
+// called with the parameters
+func (j *Job) addTask(task interface{}, params []interface{}, def TaskDefinition) bool {
+	defer j.lock()()
+
+	// record an error if the task won't be able to be executed
 	taskValue := reflect.ValueOf(task)
+	if taskValue.Kind() != reflect.Func {
+		j.setError("Do", task, ErrTaskIsNotAFuncError)
+		return false
+	}
+	taskType := taskValue.Type()
+
+	// the context of the run is injected as the first parameter
+	withContext := taskType.NumIn() == len(params)+1 && taskType.In(0) == contextType
+	offset := 0
+	if withContext {
+		offset = 1
+	}
+	if taskType.NumIn() != len(params)+offset {
+		j.setError("Do", params, ErrMissmatchedTaskParams)
+		return false
+	}
+
+	// reflect the params in to values
 	paramValues := make([]reflect.Value, len(params))
 	for i, param := range params {
+		in := taskType.In(i + offset)
+		if param == nil {
+			switch in.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+				paramValues[i] = reflect.Zero(in)
+				continue
+			}
+			j.setError("Do", param, ErrMissmatchedTaskParams)
+			return false
+		}
 		paramValues[i] = reflect.ValueOf(param)
-	}
-
-	// panic if the task won't be able to be executed
-	if taskValue.Type().NumIn() != len(paramValues) {
-		panic(ErrMissmatchedTaskParams)
-	} else if taskValue.Kind() != reflect.Func {
-		panic(ErrTaskIsNotAFuncError)
+		if !paramValues[i].Type().AssignableTo(in) {
+			j.setError("Do", param, ErrMissmatchedTaskParams)
+			return false
+		}
 	}
 
 	// add the task and its params to the job
+	j.state.mutex.Lock()
 	j.tasks = append(j.tasks, taskValue)
 	j.tasksParams = append(j.tasksParams, paramValues)
-
-	return j
+	j.tasksContext = append(j.tasksContext, withContext)
+	j.tasksDef = append(j.tasksDef, def)
+	j.state.mutex.Unlock()
+	return true
 }
 
-// At adds a time component to daily or weekly recurring tasks.
+// At adds time components to daily, weekly or monthly recurring tasks.
+// Each call adds to the times of the job, which runs at every one of them.
+// Times are "H:MM" or "H:MM:SS" on the 24-hour clock, or "H:MMam" and "H:MMpm"
+// on the 12-hour clock.
 //
 // note: if no time is specified, the `At` time will default to whenever `Schedule.Start()` is called
 //
 // Example
 //
 //  // ...
-//	Every(1).Day().At("10:30").Do(task)    // performs a task every day at 10:30 am
-//	Every(1).Monday().At("22:30").Do(task) // performs a task every Monday at 10:30 pm
-//	Every(1).Monday().Do(task)             // performs a task every Monday at whatever time `Schedule.Start()` is called
-//
-func (j *Job) At(t string) *Job {
-	hour := int((t[0]-'0')*10 + (t[1] - '0'))
-	min := int((t[3]-'0')*10 + (t[4] - '0'))
-	if hour < 0 || hour > 23 || min < 0 || min > 59 {
-		panic(ErrIncorrectTimeFormat)
-	}
-	j.atTime = time.Duration(hour) * time.Hour
-	j.atTime += time.Duration(min) * time.Minute
+//	Every(1).Day().At("10:30").Do(task)                   // performs a task every day at 10:30 am
+//	Every(1).Day().At("09:00", "13:00", "18:00").Do(task) // performs a task every day at 9 am, 1 pm and 6 pm
+//	Every(1).Monday().At("22:30").Do(task)                // performs a task every Monday at 10:30 pm
+//	Every(1).Friday().At("5:30pm").Do(task)               // performs a task every Friday at 5:30 pm
+//	Every(1).Day().At("23:59:30").Do(task)                // performs a task every day at 30 seconds before midnight
+//	Every(1).Monday().Do(task)                            // performs a task every Monday at whatever time `Schedule.Start()` is called
+//
+func (j *Job) At(times ...string) *Job {
+	defer j.lock()()
+
+	for _, t := range times {
+		atTime, err := parseAtTime(t)
+		if err != nil {
+			j.setError("At", t, err)
+			return j
+		}
+		j.addAtTime(atTime)
+	}
 	return j
 }
 
+// parseAtTime parses a time of day on the 24-hour clock, "H:MM" or "H:MM:SS",
+// or on the 12-hour clock with an "am" or "pm" suffix, "H[:MM[:SS]]am".
+// Hours may have one or two digits, minutes and seconds must have two
+func parseAtTime(t string) (time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(t))
+
+	// strip the 12-hour clock suffix
+	meridiem := ""
+	if strings.HasSuffix(s, "am") || strings.HasSuffix(s, "pm") {
+		meridiem = s[len(s)-2:]
+		s = strings.TrimSpace(s[:len(s)-2])
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 || (len(parts) == 1 && meridiem == "") {
+		return 0, fmt.Errorf("%w: %q is not H:MM, H:MM:SS or H:MMpm", ErrIncorrectTimeFormat, t)
+	}
+
+	var fields [3]int
+	for i, part := range parts {
+		// hours have one or two digits, minutes and seconds exactly two
+		if len(part) < 1 || len(part) > 2 || (i > 0 && len(part) != 2) {
+			return 0, fmt.Errorf("%w: %q is not H:MM, H:MM:SS or H:MMpm", ErrIncorrectTimeFormat, t)
+		}
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return 0, fmt.Errorf("%w: %q is not H:MM, H:MM:SS or H:MMpm", ErrIncorrectTimeFormat, t)
+			}
+		}
+		fields[i], _ = strconv.Atoi(part)
+	}
+	hour, min, sec := fields[0], fields[1], fields[2]
+
+	switch meridiem {
+	case "":
+		if hour > 23 {
+			return 0, fmt.Errorf("%w: hour %d is not between 0 and 23", ErrIncorrectTimeFormat, hour)
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return 0, fmt.Errorf("%w: hour %d is not between 1 and 12", ErrIncorrectTimeFormat, hour)
+		}
+		// 12am is midnight and 12pm is noon
+		hour %= 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+	}
+	if min > 59 {
+		return 0, fmt.Errorf("%w: minute %d is not between 0 and 59", ErrIncorrectTimeFormat, min)
+	}
+	if sec > 59 {
+		return 0, fmt.Errorf("%w: second %d is not between 0 and 59", ErrIncorrectTimeFormat, sec)
+	}
+
+	return time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second, nil
+}
+
+// addAtTime inserts a time of day into the sorted `atTimes`, ignoring duplicates
+func (j *Job) addAtTime(atTime time.Duration) {
+	i := sort.Search(len(j.atTimes), func(i int) bool { return j.atTimes[i] >= atTime })
+	if i < len(j.atTimes) && j.atTimes[i] == atTime {
+		return
+	}
+	j.atTimes = append(j.atTimes, 0)
+	copy(j.atTimes[i+1:], j.atTimes[i:])
+	j.atTimes[i] = atTime
+}
+
 // Seconds sets a job to run every `x` number of seconds
 //
 // Example
@@ -203,6 +614,8 @@ func (j *Job) At(t string) *Job {
 //	Every(5).Seconds().Do(task) // executes the task func every 5 seconds
 //
 func (j *Job) Seconds() *Job {
+	defer j.lock()()
+
 	j.unit = time.Second
 	return j
 }
@@ -220,6 +633,8 @@ func (j *Job) Second() *Job {
 //	Every(5).Minutes().Do(task) // executes the task func every 5 minutes
 //
 func (j *Job) Minutes() *Job {
+	defer j.lock()()
+
 	j.unit = time.Minute
 	return j
 }
@@ -237,6 +652,8 @@ func (j *Job) Minute() *Job {
 //	Every(5).Hours().Do(task) // executes the task func every 5 hours
 //
 func (j *Job) Hours() *Job {
+	defer j.lock()()
+
 	j.unit = time.Hour
 	return j
 }
@@ -254,6 +671,8 @@ func (j *Job) Hour() *Job {
 //	Every(5).Days().Do(task) // executes the task func every 5 days
 //
 func (j *Job) Days() *Job {
+	defer j.lock()()
+
 	j.unit = Day
 	return j
 }
@@ -263,20 +682,158 @@ func (j *Job) Day() *Job {
 	return j.Days()
 }
 
-// Weekday sets the task to be performed on a certian day of the week
+// Months sets a task to run every `x` number of months, on the day of the month
+// the scheduler was started unless `DayOfMonth` or `LastDayOfMonth` is set
+//
+// Example
+//
+//  // ...
+//	Every(3).Months().Do(task) // executes the task func every 3 months
+//
+func (j *Job) Months() *Job {
+	defer j.lock()()
+
+	j.unit = monthly
+	return j
+}
+
+// Month is an alias for `Months`
+func (j *Job) Month() *Job {
+	return j.Months()
+}
+
+// DayOfMonth sets a monthly task to run on a specific day of the month.
+// Days 1 to 31 count from the start of the month; in months shorter than the day
+// the task runs on the last day instead. Negative days count back from the end
+// of the month, e.g. -3 is 3 days before the last day.
+//
+// Example
+//
+//  // ...
+//	Every(1).Month().DayOfMonth(1).At("02:00").Do(task) // executes the task on the 1st of every month at 2 am
+//	Every(1).Month().DayOfMonth(31).Do(task)            // executes the task on the last day of every month
+//	Every(1).Month().DayOfMonth(-3).Do(task)            // executes the task 3 days before the end of every month
+//
+func (j *Job) DayOfMonth(day int) *Job {
+	defer j.lock()()
+
+	if day == 0 || day > 31 || day < -30 {
+		j.setError("DayOfMonth", day, ErrDayOfMonthNotValid)
+		return j
+	}
+	if day < 0 {
+		// -1 is the last day of the month internally
+		day--
+	}
+	j.monthDay = day
+	j.nthWeekday = 0
+	j.calendarUnit()
+	return j
+}
+
+// LastDayOfMonth sets a monthly task to run on the last day of the month,
+// i.e. the 31st, 30th, 29th or 28th depending on the month and year.
+// For quarterly tasks it is the last day of the quarter
+func (j *Job) LastDayOfMonth() *Job {
+	defer j.lock()()
+
+	j.monthDay = -1
+	j.nthWeekday = 0
+	j.calendarUnit()
+	return j
+}
+
+// Quarters sets a task to run every `x` number of quarters. Quarters start in January,
+// April, July and October, and `DayOfMonth`, `NthWeekday` and `LastWeekday` select
+// a day of the first month of the quarter, or of its last month when counting from the end.
+//
+// Example
+//
+//  // ...
+//	Every(1).Quarter().LastWeekday(time.Friday).At("17:00").Do(task) // executes the task on the last Friday of every quarter at 5 pm
+//	Every(1).Quarter().DayOfMonth(1).Do(task)                        // executes the task on January 1st, April 1st, July 1st and October 1st
+//
+func (j *Job) Quarters() *Job {
+	defer j.lock()()
+
+	j.unit = quarterly
+	return j
+}
+
+// Quarter is an alias for `Quarters`
+func (j *Job) Quarter() *Job {
+	return j.Quarters()
+}
+
+// calendarUnit makes the job monthly unless it is already monthly or quarterly
+func (j *Job) calendarUnit() {
+	if j.unit != quarterly {
+		j.unit = monthly
+	}
+}
+
+// Weekday sets the task to be performed on a certian day of the week.
+// Each call adds to the days of the job, which runs on every one of them.
 //
 // Example
 //
 //  // ...
 //  scheduler.Every(1).Weekday(time.Sunday).Do(task) // executes the task once every Sunday at whatever time `Scheduler.Start()` is called
 //  scheduler.Every(2).Weekday(time.Monday).At("05:00").Do(task) // executes the task every other Monday at 7 am
+//  scheduler.Every(1).Monday().Wednesday().At("09:00", "18:00").Do(task) // executes the task on Mondays and Wednesdays at 9 am and 6 pm
 //
 func (j *Job) Weekday(weekday time.Weekday) *Job {
-	j.weekDay = weekday
+	defer j.lock()()
+
+	if j.unit != Week {
+		j.weekDays = nil
+	}
+	i := sort.Search(len(j.weekDays), func(i int) bool { return j.weekDays[i] >= weekday })
+	if i == len(j.weekDays) || j.weekDays[i] != weekday {
+		j.weekDays = append(j.weekDays, 0)
+		copy(j.weekDays[i+1:], j.weekDays[i:])
+		j.weekDays[i] = weekday
+	}
 	j.unit = Week
 	return j
 }
 
+// Weekdays is an alias for calling `Weekday` with each of the days
+func (j *Job) Weekdays(weekdays ...time.Weekday) *Job {
+	for _, weekday := range weekdays {
+		j.Weekday(weekday)
+	}
+	return j
+}
+
+// NthWeekday sets a monthly task to run on the nth weekday of the month, from 1 to 4.
+// Negative values count back from the end of the month, e.g. -1 is the last weekday of the month.
+// For quarterly tasks it is the nth weekday of the quarter.
+//
+// Example
+//
+//  // ...
+//	Every(1).Month().NthWeekday(2, time.Tuesday).At("09:00").Do(task) // executes the task on the second Tuesday of every month at 9 am
+//	Every(1).Month().NthWeekday(-2, time.Sunday).Do(task)             // executes the task on the second to last Sunday of every month
+//
+func (j *Job) NthWeekday(n int, weekday time.Weekday) *Job {
+	defer j.lock()()
+
+	if n == 0 || n > 4 || n < -4 {
+		j.setError("NthWeekday", n, ErrNthWeekdayNotValid)
+		return j
+	}
+	j.nthWeekday = n
+	j.weekDays = []time.Weekday{weekday}
+	j.calendarUnit()
+	return j
+}
+
+// LastWeekday is an alias for `NthWeekday(-1, weekday)`
+func (j *Job) LastWeekday(weekday time.Weekday) *Job {
+	return j.NthWeekday(-1, weekday)
+}
+
 // Monday is an alias for `Weekday(time.Monday)`
 func (j *Job) Monday() *Job {
 	return j.Weekday(time.Monday)
@@ -312,14 +869,33 @@ func (j *Job) Sunday() *Job {
 	return j.Weekday(time.Sunday)
 }
 
-// Weeks is an alias for `Weekday(time.Now().Weekday())`
+// Weeks is an alias for `Weekday(...)` with the current day of the week
 func (j *Job) Weeks() *Job {
-	return j.Weekday(time.Now().Weekday())
+	return j.Weekday(j.now().Weekday())
 }
 
-// Week is an alias for `Weekday(time.Now().Weekday())`
+// Week is an alias for `Weekday(...)` with the current day of the week
 func (j *Job) Week() *Job {
-	return j.Weekday(time.Now().Weekday())
+	return j.Weekday(j.now().Weekday())
+}
+
+// Schedule sets a custom schedule that computes the run times of the job,
+// overriding the interval and unit of the job.
+//
+// Example
+//
+//  // ...
+//  type businessDays struct{}
+//
+//  func (businessDays) Next(after time.Time) time.Time { ... }
+//
+//  Every(1).Schedule(businessDays{}).Do(task) // executes the task whenever `businessDays` says so
+//
+func (j *Job) Schedule(schedule Schedule) *Job {
+	defer j.lock()()
+
+	j.schedule = schedule
+	return j
 }
 
 // Location sets the timezone of the job.
@@ -336,6 +912,8 @@ func (j *Job) Week() *Job {
 //  Every(2).Monday().At("05:00").Location(est).Do(task) // executes the task every monday at 5:00 am eastern standard time
 //
 func (j *Job) Location(loc *time.Location) *Job {
+	defer j.lock()()
+
 	j.location = loc
 	return j
 }
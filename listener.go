@@ -0,0 +1,168 @@
+package gocron
+
+import "time"
+
+// Listener is notified of the lifecycle of a scheduler and its jobs.
+// Embed `NopListener` to only implement some of the callbacks.
+//
+// Listeners are called in the order of the events from a single goroutine of their own,
+// shortly after the events happen, and never while the scheduler holds its lock, so
+// they can call the scheduler, including `Stop`. They should return quickly, since
+// the following events wait for them
+//
+// Example
+//
+//  // ...
+//	type failureLogger struct {
+//		gocron.NopListener
+//	}
+//
+//	func (failureLogger) RunFailed(job *gocron.Job, d time.Duration, err error) {
+//		log.Printf("job %q failed after %v: %v", job.Name(), d, err)
+//	}
+//
+//	s.AddListener(failureLogger{})
+//
+type Listener interface {
+	// JobAdded is called when a job is created with `Every`, `EveryWithName`, `Cron` or `Emergency`
+	JobAdded(job *Job)
+
+	// JobRemoved is called when a job is removed, cleared, or replaced by `EveryWithName`
+	JobRemoved(job *Job)
+
+	// JobPaused is called when a job is paused by `PauseWithName` or `PauseAll`
+	JobPaused(job *Job)
+
+	// JobResumed is called when a job is resumed by `ResumeWithName` or `ResumeAll`
+	JobResumed(job *Job)
+
+	// JobIntervalUpdated is called when the interval of a job is updated by `UpdateIntervalWithName`
+	JobIntervalUpdated(job *Job, interval uint64)
+
+	// RunStarted is called when a run of a job starts, before its first attempt,
+	// with the time the run was scheduled at
+	RunStarted(job *Job, scheduled time.Time)
+
+	// RunSucceeded is called when a run of a job succeeds, with the duration of the run including retries
+	RunSucceeded(job *Job, duration time.Duration)
+
+	// RunFailed is called when a run of a job fails, with the duration of the run including
+	// retries and the error of its last attempt
+	RunFailed(job *Job, duration time.Duration, err error)
+
+	// RunSkipped is called when a run of a job is dropped by `OverlapSkip`, `MisfireSkip` or `Shutdown`
+	RunSkipped(job *Job)
+
+	// StoreFailed is called when the `JobStore` of the scheduler fails to load, save or delete the state of a job
+	StoreFailed(job *Job, err error)
+
+	// SchedulerStarted is called when the scheduler is started by `Start`
+	SchedulerStarted()
+
+	// SchedulerStopped is called when the scheduler is stopped by `Stop`
+	SchedulerStopped()
+}
+
+// NopListener implements every callback of `Listener` with a no-op
+type NopListener struct{}
+
+// JobAdded does nothing
+func (NopListener) JobAdded(job *Job) {}
+
+// JobRemoved does nothing
+func (NopListener) JobRemoved(job *Job) {}
+
+// JobPaused does nothing
+func (NopListener) JobPaused(job *Job) {}
+
+// JobResumed does nothing
+func (NopListener) JobResumed(job *Job) {}
+
+// JobIntervalUpdated does nothing
+func (NopListener) JobIntervalUpdated(job *Job, interval uint64) {}
+
+// RunStarted does nothing
+func (NopListener) RunStarted(job *Job, scheduled time.Time) {}
+
+// RunSucceeded does nothing
+func (NopListener) RunSucceeded(job *Job, duration time.Duration) {}
+
+// RunFailed does nothing
+func (NopListener) RunFailed(job *Job, duration time.Duration, err error) {}
+
+// RunSkipped does nothing
+func (NopListener) RunSkipped(job *Job) {}
+
+// StoreFailed does nothing
+func (NopListener) StoreFailed(job *Job, err error) {}
+
+// SchedulerStarted does nothing
+func (NopListener) SchedulerStarted() {}
+
+// SchedulerStopped does nothing
+func (NopListener) SchedulerStopped() {}
+
+// event is a callback of the listeners
+type event func(Listener)
+
+// AddListener registers a listener notified of the lifecycle of the scheduler and its jobs
+func (s *scheduler) AddListener(listener Listener) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+// emit queues an event until `notify` is called. The mutex must be held.
+// Methods emitting events defer `notify` before locking the mutex, so the events
+// are delivered once it is released
+func (s *scheduler) emit(e event) {
+	if len(s.listeners) > 0 {
+		s.events = append(s.events, e)
+	}
+}
+
+// notify makes the queued writes to the store, and hands the queued events over to
+// the delivery goroutine, starting it if it isn't running. The mutex must not be held
+func (s *scheduler) notify() {
+	s.flush()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.events) > 0 && !s.delivering {
+		s.delivering = true
+		go s.deliver()
+	}
+}
+
+// deliver delivers the queued events to the listeners until the queue is empty. A single
+// goroutine delivers the events at a time, so the listeners are called in the order the
+// events were emitted, and never from a goroutine of the scheduler, e.g. its run loop
+func (s *scheduler) deliver() {
+	for {
+		s.mutex.Lock()
+		events, listeners := s.events, s.listeners
+		s.events = nil
+		if len(events) == 0 {
+			s.delivering = false
+			s.mutex.Unlock()
+			return
+		}
+		s.mutex.Unlock()
+
+		for _, e := range events {
+			for _, listener := range listeners {
+				e(listener)
+			}
+		}
+	}
+}
+
+// publish queues an event, and hands it over to the delivery goroutine. The mutex must not be held
+func (s *scheduler) publish(e event) {
+	s.mutex.Lock()
+	s.emit(e)
+	s.mutex.Unlock()
+	s.notify()
+}
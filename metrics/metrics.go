@@ -0,0 +1,262 @@
+// Package metrics exports the metrics of a gocron scheduler and its jobs
+// in the Prometheus text exposition format.
+//
+// Example
+//
+//  // ...
+//	s := gocron.NewScheduler()
+//	http.Handle("/metrics", metrics.NewCollector(s))
+//
+// The per-job metrics are labeled by the name of the job, see `gocron.EveryWithName`.
+// Jobs created without a name are only counted in the metrics of the scheduler
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taka-wang/gocron"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of the run duration histogram
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// contentType is the content type of the text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Collector collects the metrics of a scheduler, and serves them over HTTP.
+// It is a `gocron.Listener` of the scheduler
+type Collector struct {
+	gocron.NopListener
+
+	scheduler gocron.Scheduler
+	clock     gocron.Clock
+	buckets   []float64
+
+	mutex sync.Mutex
+	jobs  map[string]*jobMetrics
+}
+
+// jobMetrics are the metrics of a named job
+type jobMetrics struct {
+	runs     uint64
+	failures uint64
+	skipped  uint64
+	running  int64
+
+	// lag between the time the last run was scheduled at and its start, in seconds
+	lag float64
+
+	// cumulative counts of the run durations in each bucket
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// NewCollector creates a collector of the metrics of the scheduler, and registers it as
+// a listener of the scheduler. Only the runs that start after it is created are counted
+func NewCollector(s gocron.Scheduler) *Collector {
+	c := &Collector{
+		scheduler: s,
+		clock:     s.Clock(),
+		buckets:   DefaultBuckets,
+		jobs:      make(map[string]*jobMetrics),
+	}
+	s.AddListener(c)
+	return c
+}
+
+// job returns the metrics of the job, or nil if it has no name. The mutex must be held
+func (c *Collector) job(job *gocron.Job) *jobMetrics {
+	if job.Name() == "" {
+		return nil
+	}
+	m, ok := c.jobs[job.Name()]
+	if !ok {
+		m = &jobMetrics{buckets: make([]uint64, len(c.buckets))}
+		c.jobs[job.Name()] = m
+	}
+	return m
+}
+
+// RunStarted records the lag of the run
+func (c *Collector) RunStarted(job *gocron.Job, scheduled time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if m := c.job(job); m != nil {
+		m.running++
+		m.lag = c.clock.Now().Sub(scheduled).Seconds()
+	}
+}
+
+// RunSucceeded records the duration of the run
+func (c *Collector) RunSucceeded(job *gocron.Job, duration time.Duration) {
+	c.finished(job, duration, false)
+}
+
+// RunFailed records the duration and the failure of the run
+func (c *Collector) RunFailed(job *gocron.Job, duration time.Duration, err error) {
+	c.finished(job, duration, true)
+}
+
+// RunSkipped counts the skipped run
+func (c *Collector) RunSkipped(job *gocron.Job) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if m := c.job(job); m != nil {
+		m.skipped++
+	}
+}
+
+// JobRemoved drops the metrics of the job, unless it was replaced by a job with the
+// same name, e.g. by `EveryWithName` or a config reload, which keeps counting them
+func (c *Collector) JobRemoved(job *gocron.Job) {
+	// listeners are never called while the scheduler holds its lock
+	for _, j := range c.scheduler.Jobs() {
+		if j.Name() == job.Name() {
+			return
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.jobs, job.Name())
+}
+
+// finished records a finished run
+func (c *Collector) finished(job *gocron.Job, duration time.Duration, failed bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	m := c.job(job)
+	if m == nil {
+		return
+	}
+	// runs started before the collector was created aren't counted as running
+	if m.running > 0 {
+		m.running--
+	}
+	m.runs++
+	if failed {
+		m.failures++
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			m.buckets[i]++
+		}
+	}
+	m.sum += seconds
+	m.count++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics to `w` in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	// list the jobs before locking the collector, so the scheduler
+	// is never called while holding the lock
+	jobs := c.scheduler.Jobs()
+	paused := 0
+	for _, job := range jobs {
+		if job.IsPaused() {
+			paused++
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// export the jobs that haven't run yet too, and drop the metrics of the jobs
+	// removed from the scheduler whose last run finished after they were removed
+	names := make([]string, 0, len(jobs))
+	scheduled := make(map[string]*jobMetrics, len(jobs))
+	for _, job := range jobs {
+		if _, ok := scheduled[job.Name()]; ok {
+			continue
+		}
+		if m := c.job(job); m != nil {
+			names = append(names, job.Name())
+			scheduled[job.Name()] = m
+		}
+	}
+	c.jobs = scheduled
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	header(&b, "gocron_jobs_scheduled", "gauge", "Number of jobs in the scheduler.")
+	fmt.Fprintf(&b, "gocron_jobs_scheduled %d\n", len(jobs))
+	header(&b, "gocron_jobs_paused", "gauge", "Number of paused jobs in the scheduler.")
+	fmt.Fprintf(&b, "gocron_jobs_paused %d\n", paused)
+
+	counters := []struct {
+		name, help string
+		value      func(*jobMetrics) uint64
+	}{
+		{"gocron_job_runs_total", "Number of finished runs of the job.", func(m *jobMetrics) uint64 { return m.runs }},
+		{"gocron_job_failures_total", "Number of failed runs of the job.", func(m *jobMetrics) uint64 { return m.failures }},
+		{"gocron_job_skipped_runs_total", "Number of runs of the job dropped by its overlap or misfire policy.", func(m *jobMetrics) uint64 { return m.skipped }},
+	}
+	for _, counter := range counters {
+		header(&b, counter.name, "counter", counter.help)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s{job=%s} %d\n", counter.name, label(name), counter.value(c.jobs[name]))
+		}
+	}
+
+	header(&b, "gocron_job_running", "gauge", "Number of in-progress runs of the job.")
+	for _, name := range names {
+		fmt.Fprintf(&b, "gocron_job_running{job=%s} %d\n", label(name), c.jobs[name].running)
+	}
+
+	header(&b, "gocron_job_lag_seconds", "gauge", "Delay between the time the last run of the job was scheduled at and its start.")
+	for _, name := range names {
+		fmt.Fprintf(&b, "gocron_job_lag_seconds{job=%s} %s\n", label(name), float(c.jobs[name].lag))
+	}
+
+	header(&b, "gocron_job_run_duration_seconds", "histogram", "Duration of the finished runs of the job, including retries.")
+	for _, name := range names {
+		m := c.jobs[name]
+		for i, bound := range c.buckets {
+			fmt.Fprintf(&b, "gocron_job_run_duration_seconds_bucket{job=%s,le=\"%s\"} %d\n", label(name), float(bound), m.buckets[i])
+		}
+		fmt.Fprintf(&b, "gocron_job_run_duration_seconds_bucket{job=%s,le=\"+Inf\"} %d\n", label(name), m.count)
+		fmt.Fprintf(&b, "gocron_job_run_duration_seconds_sum{job=%s} %s\n", label(name), float(m.sum))
+		fmt.Fprintf(&b, "gocron_job_run_duration_seconds_count{job=%s} %d\n", label(name), m.count)
+	}
+
+	return b.WriteTo(w)
+}
+
+// header writes the help and type lines of a metric
+func header(b *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// labelEscaper escapes the characters that can't appear as is in a label value
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label returns the quoted label value
+func label(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// float formats a sample value
+func float(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,155 @@
+// Tests for the metrics of gocron
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taka-wang/gocron"
+	"github.com/taka-wang/gocron/internal/sugar"
+)
+
+// finished is a listener signaling the finished runs. Listeners are notified in order,
+// so the collector added before it has recorded a run when it is signaled
+type finished struct {
+	gocron.NopListener
+	runs chan bool
+}
+
+func (f finished) RunSucceeded(job *gocron.Job, d time.Duration)         { f.runs <- true }
+func (f finished) RunFailed(job *gocron.Job, d time.Duration, err error) { f.runs <- true }
+
+// wait waits for `n` runs to finish
+func (f finished) wait(n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-f.runs:
+		case <-time.After(5 * time.Second):
+			return
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("Metrics collector")
+
+	s.Assert("the collector serves the metrics of the jobs in the text exposition format", func(log sugar.Log) bool {
+		clock := gocron.NewFakeClock(time.Date(2016, time.January, 6, 10, 20, 30, 0, time.Local))
+		scheduler := gocron.NewScheduler(gocron.WithClock(clock))
+		collector := NewCollector(scheduler)
+		runs := finished{runs: make(chan bool, 10)}
+		scheduler.AddListener(runs)
+
+		fail := false
+		scheduler.EveryWithName(1, "report").Second().Do(func() error {
+			if fail {
+				return errors.New("oops")
+			}
+			return nil
+		})
+		scheduler.EveryWithName(1, "cleanup").Day().Do(func() {})
+		scheduler.PauseWithName("cleanup")
+
+		// the first call initializes the jobs
+		scheduler.RunPending()
+		clock.Advance(time.Second)
+		scheduler.RunPending()
+		fail = true
+		clock.Advance(time.Second)
+		scheduler.RunPending()
+		runs.wait(2)
+
+		w := httptest.NewRecorder()
+		collector.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+		body := w.Body.String()
+		log("%s", body)
+
+		expected := []string{
+			"# TYPE gocron_job_runs_total counter\n",
+			"gocron_jobs_scheduled 2\n",
+			"gocron_jobs_paused 1\n",
+			`gocron_job_runs_total{job="report"} 2` + "\n",
+			`gocron_job_failures_total{job="report"} 1` + "\n",
+			`gocron_job_running{job="report"} 0` + "\n",
+			`gocron_job_run_duration_seconds_bucket{job="report",le="0.005"} 2` + "\n",
+			`gocron_job_run_duration_seconds_bucket{job="report",le="+Inf"} 2` + "\n",
+			`gocron_job_run_duration_seconds_count{job="report"} 2` + "\n",
+		}
+		for _, line := range expected {
+			if !strings.Contains(body, line) {
+				log("missing %q", line)
+				return false
+			}
+		}
+		return strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") &&
+			strings.Contains(body, `gocron_job_runs_total{job="cleanup"} 0`+"\n")
+	})
+
+	s.Assert("the collector only exports the named jobs of the scheduler, lagging by its clock", func(log sugar.Log) bool {
+		clock := gocron.NewFakeClock(time.Date(2016, time.January, 6, 10, 20, 30, 0, time.Local))
+		scheduler := gocron.NewScheduler(gocron.WithClock(clock))
+		collector := NewCollector(scheduler)
+		runs := finished{runs: make(chan bool, 10)}
+		scheduler.AddListener(runs)
+
+		scheduler.EveryWithName(1, "report").Second().Do(func() {})
+		scheduler.EveryWithName(1, "cleanup").Second().Do(func() {})
+		scheduler.Every(1).Second().Do(func() {})
+
+		scheduler.RunPending()
+		clock.Advance(1500 * time.Millisecond)
+		scheduler.RunPending()
+		runs.wait(3)
+		scheduler.RemoveWithName("cleanup")
+
+		var b bytes.Buffer
+		collector.WriteTo(&b)
+		body := b.String()
+		log("%s", body)
+
+		return strings.Contains(body, `gocron_job_lag_seconds{job="report"} 0.5`+"\n") &&
+			strings.Contains(body, "gocron_jobs_scheduled 2\n") &&
+			!strings.Contains(body, `job="cleanup"`) && !strings.Contains(body, `job=""`)
+	})
+
+	s.Assert("the collector keeps counting the runs of a job replaced by a job with the same name", func(log sugar.Log) bool {
+		clock := gocron.NewFakeClock(time.Date(2016, time.January, 6, 10, 20, 30, 0, time.Local))
+		scheduler := gocron.NewScheduler(gocron.WithClock(clock))
+		collector := NewCollector(scheduler)
+		runs := finished{runs: make(chan bool, 10)}
+		scheduler.AddListener(runs)
+
+		scheduler.EveryWithName(1, "report").Second().Do(func() {})
+		scheduler.RunPending()
+		for i := 0; i < 2; i++ {
+			clock.Advance(time.Second)
+			scheduler.RunPending()
+		}
+		runs.wait(2)
+
+		// the replacement is initialized by the first call, and the removal of the replaced
+		// job is recorded before its run finishes since listeners are notified in order
+		scheduler.EveryWithName(2, "report").Seconds().Do(func() {})
+		scheduler.RunPending()
+		clock.Advance(2 * time.Second)
+		scheduler.RunPending()
+		runs.wait(1)
+
+		var b bytes.Buffer
+		collector.WriteTo(&b)
+		body := b.String()
+		log("%s", body)
+		return strings.Contains(body, `gocron_job_runs_total{job="report"} 3`+"\n")
+	})
+
+	s.Assert("label values are escaped", func(log sugar.Log) bool {
+		return label("a\"b\\c\nd") == `"a\"b\\c\nd"`
+	})
+}
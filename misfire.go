@@ -0,0 +1,157 @@
+package gocron
+
+import "time"
+
+// MisfirePolicy decides what happens to the runs of a job the scheduler missed,
+// e.g. because the process was suspended, or `RunPending` wasn't called in time
+type MisfirePolicy int
+
+const (
+	// MisfireRunOnce runs the job once for all of the missed runs, then realigns
+	// its next run on the first slot in the future. It is the default policy
+	MisfireRunOnce MisfirePolicy = iota
+
+	// MisfireRunAll runs the job once for every missed run, all at once, up to the
+	// `MaxCatchUpRuns` most recent ones. The older ones are dropped and counted in
+	// `Job.MissedRuns`, an estimate for the schedules without a fixed interval.
+	// Combine it with `OverlapQueue` to run them one after another
+	MisfireRunAll
+
+	// MisfireSkip drops the missed runs, counts them in `Job.MissedRuns`,
+	// and realigns the next run of the job on the first slot in the future
+	MisfireSkip
+)
+
+// DefaultMisfireThreshold is the misfire threshold of the jobs that don't set one
+const DefaultMisfireThreshold = time.Second
+
+// MaxCatchUpRuns is the maximum number of missed runs `MisfireRunAll` runs at once,
+// so a job every second doesn't dispatch days of runs after a long suspend. It also
+// bounds the missed slots of a schedule without a fixed interval, e.g. a cron expression,
+// walked one by one while the scheduler holds its lock. The job then jumps over the others
+const MaxCatchUpRuns = 100
+
+// Misfire sets what happens to the runs of the job the scheduler missed.
+// A run is missed when the scheduler is more than `threshold` late for it,
+// `DefaultMisfireThreshold` when 0. Runs less late than that run as usual
+//
+// Example
+//
+//  // ...
+//	Every(1).Minute().Misfire(MisfireSkip, 10*time.Second).Do(task)           // drops the runs more than 10 seconds late
+//	Every(1).Hour().Misfire(MisfireRunAll, 0).Overlap(OverlapQueue).Do(task) // catches up on every missed hour
+//
+func (j *Job) Misfire(policy MisfirePolicy, threshold time.Duration) *Job {
+	defer j.lock()()
+
+	j.misfire = policy
+	j.misfireThreshold = threshold
+	return j
+}
+
+// MissedRuns returns the number of runs dropped by `MisfireSkip`, and by `MisfireRunAll`
+// beyond `MaxCatchUpRuns`. The runs of a schedule without a fixed interval missed
+// beyond `MaxCatchUpRuns` slots are estimated from the previous ones
+func (j *Job) MissedRuns() uint64 {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	return j.state.missed
+}
+
+// catchUp moves the job, due at `now`, to its next run according to its misfire policy.
+// It returns the scheduled times of the runs to dispatch. The scheduler calls it while holding its lock
+func (j *Job) catchUp(now time.Time) []time.Time {
+	threshold := j.misfireThreshold
+	if threshold <= 0 {
+		threshold = DefaultMisfireThreshold
+	}
+	scheduled := j.nextRun
+	if !now.After(scheduled.Add(threshold)) {
+		j.advance()
+		return []time.Time{scheduled}
+	}
+
+	switch j.misfire {
+	case MisfireRunAll:
+		dropped := 0
+
+		// skip the missed slots of a fixed interval before the most recent ones at once
+		if interval, ok := j.resolved.(*intervalSchedule); ok && interval.every > 0 {
+			if n := int(now.Sub(j.nextRun)/interval.every) + 1 - MaxCatchUpRuns; n > 0 {
+				j.nextRun = j.nextRun.Add(time.Duration(n) * interval.every)
+				dropped = n
+			}
+		}
+		var runs []time.Time
+		for walked, jumped := 0, false; !j.nextRun.After(now); walked++ {
+			if walked == 3*MaxCatchUpRuns {
+				dropped += j.realign(now)
+				break
+			}
+			if len(runs) == MaxCatchUpRuns {
+				// jump close to the most recent runs of the other schedules at once,
+				// estimating the number of slots jumped over from the walked ones
+				period := runs[len(runs)-1].Sub(runs[0]) / time.Duration(len(runs)-1)
+				if from := now.Add(-period * MaxCatchUpRuns); !jumped && from.After(j.nextRun) {
+					if next := j.next(from); !next.After(now) {
+						dropped += len(runs) + int(next.Sub(j.nextRun)/period)
+						runs, j.nextRun, jumped = runs[:0], next, true
+						continue
+					}
+				}
+				runs = append(runs[:0], runs[1:]...)
+				dropped++
+			}
+			runs = append(runs, j.nextRun)
+			j.advance()
+		}
+		if dropped > 0 {
+			j.state.mutex.Lock()
+			j.state.missed += uint64(dropped)
+			j.state.mutex.Unlock()
+		}
+		return runs
+	case MisfireSkip:
+		missed := j.realign(now)
+		j.state.mutex.Lock()
+		j.state.missed += uint64(missed)
+		j.state.mutex.Unlock()
+		return nil
+	default:
+		j.realign(now)
+		j.lastRun = scheduled
+		return []time.Time{scheduled}
+	}
+}
+
+// realign moves `nextRun` to the first slot of the schedule after `now`. It returns
+// the number of slots it skipped, an estimate past `MaxCatchUpRuns` slots
+func (j *Job) realign(now time.Time) int {
+	skipped := 0
+
+	// skip the missed slots of a fixed interval at once, even after a long suspend
+	if interval, ok := j.resolved.(*intervalSchedule); ok && interval.every > 0 {
+		if n := int(now.Sub(j.nextRun) / interval.every); n > 0 {
+			j.nextRun = j.nextRun.Add(time.Duration(n) * interval.every)
+			skipped = n
+		}
+	}
+	first := j.nextRun
+	for walked := 0; !j.nextRun.After(now); walked++ {
+		if walked == MaxCatchUpRuns {
+			// jump over the remaining slots of the other schedules at once,
+			// estimating their number from the walked ones
+			period := j.nextRun.Sub(first) / MaxCatchUpRuns
+			next := j.next(now)
+			if next != never && period > 0 {
+				skipped += int(next.Sub(j.nextRun) / period)
+			}
+			j.nextRun = next
+			return skipped
+		}
+		j.nextRun = j.next(j.nextRun)
+		skipped++
+	}
+	return skipped
+}
@@ -0,0 +1,58 @@
+package gocron
+
+// Option configures a scheduler created by `NewScheduler`
+type Option func(*scheduler)
+
+// WithMaxConcurrency limits the number of job runs executing at the same time to `n`.
+// Runs that are due while `n` runs are executing wait for one of them to finish,
+// without a goroutine of their own.
+// By default, or when `n` is 0, the number of concurrent runs is unbounded.
+//
+// Example
+//
+//  // ...
+//  s := NewScheduler(WithMaxConcurrency(4)) // at most 4 tasks run at once
+//
+func WithMaxConcurrency(n int) Option {
+	return func(s *scheduler) {
+		if n < 0 {
+			n = 0
+		}
+		s.maxWorkers = n
+	}
+}
+
+// WithStore sets the store persisting the state of the named jobs, so they resume
+// where they left off when the process restarts. By default the state is only kept in memory.
+// A store that is also a `Listener`, e.g. to record the runs of the jobs, is added to the listeners
+//
+// Example
+//
+//  // ...
+//  store, err := NewFileStore("jobs.json")
+//  ...
+//  s := NewScheduler(WithStore(store)) // jobs created by `EveryWithName` are restored from jobs.json
+//
+func WithStore(store JobStore) Option {
+	return func(s *scheduler) {
+		s.store = store
+		if listener, ok := store.(Listener); ok {
+			s.listeners = append(s.listeners, listener)
+		}
+	}
+}
+
+// WithClock sets the clock the scheduler reads the time from and sleeps with.
+// By default the scheduler uses the system clock
+//
+// Example
+//
+//  // ...
+//  clock := NewFakeClock(time.Now())
+//  s := NewScheduler(WithClock(clock)) // runs the jobs when the clock is advanced
+//
+func WithClock(clock Clock) Option {
+	return func(s *scheduler) {
+		s.clock = clock
+	}
+}
@@ -0,0 +1,89 @@
+package gocron
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// never is the next run of the jobs that never run, e.g. because their schedule has no more runs, or they were
+// built with an invalid argument. It keeps them at the bottom of the queue
+var never = time.Unix(1<<62, 0)
+
+// jobQueue is a min-heap of jobs keyed by their next run, so the scheduler
+// finds the pending jobs without sorting all of its jobs on every run.
+// Jobs with the same next run are ordered by interval, then by the order they were added in.
+// It implements `heap.Interface`, and must only be changed through the `heap` functions
+type jobQueue []*Job
+
+// Len returns the number of jobs in the queue
+func (q jobQueue) Len() int {
+	return len(q)
+}
+
+// Less returns true if the ith job runs before the jth job
+func (q jobQueue) Less(i, j int) bool {
+	if !q[i].nextRun.Equal(q[j].nextRun) {
+		return q[i].nextRun.Before(q[j].nextRun)
+	}
+	if q[i].interval != q[j].interval {
+		return q[i].interval < q[j].interval
+	}
+	return q[i].seq < q[j].seq
+}
+
+// Swap swaps two jobs, and updates their index
+func (q jobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+// Push appends a job to the queue. Use `heap.Push` to add a job
+func (q *jobQueue) Push(x interface{}) {
+	job := x.(*Job)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+// Pop removes the last job of the queue. Use `heap.Pop` or `heap.Remove` to remove a job
+func (q *jobQueue) Pop() interface{} {
+	old := *q
+	job := old[len(old)-1]
+	// don't keep a reference to the removed job
+	old[len(old)-1] = nil
+	job.index = -1
+	*q = old[:len(old)-1]
+	return job
+}
+
+// contains returns true if the job is in the queue
+func (q jobQueue) contains(job *Job) bool {
+	return job.index >= 0 && job.index < len(q) && q[job.index] == job
+}
+
+// add adds a job to the queue in O(log n)
+func (q *jobQueue) add(job *Job) {
+	heap.Push(q, job)
+}
+
+// remove removes a job from the queue in O(log n). It returns false if the job isn't in the queue
+func (q *jobQueue) remove(job *Job) bool {
+	if !q.contains(job) {
+		return false
+	}
+	heap.Remove(q, job.index)
+	return true
+}
+
+// sorted returns a copy of the jobs sorted by their next run, in O(n log n)
+func (q jobQueue) sorted() []*Job {
+	jobs := append(jobQueue(nil), q...)
+	sort.Slice(jobs, jobs.Less)
+	return jobs
+}
+
+// update restores the order of the queue in O(log n) after the next run of the job changed
+func (q *jobQueue) update(job *Job) {
+	heap.Fix(q, job.index)
+}
@@ -0,0 +1,131 @@
+package gocron
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// registry maps the names of the registered tasks onto their func
+var registry = struct {
+	sync.RWMutex
+	tasks map[string]reflect.Value
+}{tasks: make(map[string]reflect.Value)}
+
+// RegisterTask registers a task under a name, so jobs can refer to it by name with
+// `DoTask`, and be saved and restored as a `JobDefinition`. The parameters of the
+// task must be JSON serializable, except for an optional `context.Context` first parameter.
+// It returns an error if the task is not a func or the name is already registered
+//
+// Example
+//
+//  // ...
+//	gocron.RegisterTask("email", func(ctx context.Context, to string, retries int) error { ... })
+//	s.EveryWithName(1, "digest").Day().At("8:00").DoTask("email", "team@example.com", 3)
+//
+func RegisterTask(name string, task interface{}) error {
+	taskValue := reflect.ValueOf(task)
+	if taskValue.Kind() != reflect.Func {
+		return fmt.Errorf("%w: task %q", ErrTaskIsNotAFuncError, name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.tasks[name]; ok {
+		return fmt.Errorf("%w: %q", ErrTaskAlreadyRegistered, name)
+	}
+	registry.tasks[name] = taskValue
+	return nil
+}
+
+// RegisteredTasks returns the names of the registered tasks, sorted
+func RegisteredTasks() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.tasks))
+	for name := range registry.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupTask returns the task registered under the name
+func lookupTask(name string) (reflect.Value, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	task, ok := registry.tasks[name]
+	return task, ok
+}
+
+// DoTask specifies the registered task that should be executed, and the arguments
+// it should be passed. The arguments are converted to the parameters of the task
+// through JSON, e.g. a float64 argument is passed to an int parameter, and are kept
+// in the `JobDefinition` of the job. Like `Do`, it must be called last.
+//
+// Example
+//
+//  // ...
+//	RegisterTask("cleanup", func(dir string, maxAge int) { ... })
+//	Every(1).Hour().DoTask("cleanup", "/tmp", 3600) // performs `cleanup("/tmp", 3600)` every hour
+//
+func (j *Job) DoTask(name string, args ...interface{}) *Job {
+	def := TaskDefinition{Name: name, Args: make([]json.RawMessage, len(args))}
+	for i, arg := range args {
+		raw, err := json.Marshal(arg)
+		if err != nil {
+			j.recordError("DoTask", arg, err)
+			return j
+		}
+		def.Args[i] = raw
+	}
+	return j.doTask(def)
+}
+
+// doTask adds the registered task of the definition to the job,
+// with its JSON arguments decoded into the parameters of the task
+func (j *Job) doTask(def TaskDefinition) *Job {
+	task, ok := lookupTask(def.Name)
+	if !ok {
+		j.recordError("DoTask", def.Name, ErrTaskNotRegistered)
+		return j
+	}
+	params, err := decodeArgs(task.Type(), def.Args)
+	if err != nil {
+		j.recordError("DoTask", def.Name, err)
+		return j
+	}
+
+	// the scheduler waits for the first task before running the job
+	if j.addTask(task.Interface(), params, def) && j.scheduler != nil {
+		j.scheduler.schedule(j)
+	}
+	return j
+}
+
+// decodeArgs decodes the JSON arguments into values of the parameters of the task,
+// skipping the context of the run if the task takes one
+func decodeArgs(taskType reflect.Type, args []json.RawMessage) ([]interface{}, error) {
+	offset := 0
+	if taskType.NumIn() == len(args)+1 && taskType.In(0) == contextType {
+		offset = 1
+	}
+	if taskType.NumIn() != len(args)+offset {
+		return nil, fmt.Errorf("%w: expected %d arguments, found %d", ErrMissmatchedTaskParams, taskType.NumIn()-offset, len(args))
+	}
+
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		param := reflect.New(taskType.In(i + offset))
+		if err := json.Unmarshal(arg, param.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: argument %d: %v", ErrMissmatchedTaskParams, i, err)
+		}
+		params[i] = param.Elem().Interface()
+	}
+	return params, nil
+}
@@ -0,0 +1,82 @@
+package gocron
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy describes how a failed run of a job is retried. A retry resumes the run
+// from the task that failed, the tasks that succeeded before it are not called again.
+// Retries never shift the regular schedule of the job: a retry that would start
+// at or after the next regular run is not attempted.
+//
+// Example
+//
+//  // ...
+//	Every(1).Hour().Retry(RetryPolicy{
+//		MaxAttempts:  5,
+//		InitialDelay: time.Second,
+//		Multiplier:   2,
+//		MaxDelay:     time.Minute,
+//		Jitter:       0.1,
+//	}).Do(task) // retries a failed run after about 1s, 2s, 4s and 8s
+//
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a run is attempted, including
+	// the first attempt. The run is not retried when it is 0 or 1
+	MaxAttempts int
+
+	// InitialDelay is the delay before the first retry
+	InitialDelay time.Duration
+
+	// Multiplier is the factor the delay grows by after each retry, 2 when not set
+	Multiplier float64
+
+	// MaxDelay caps the delay between retries, unbounded when 0
+	MaxDelay time.Duration
+
+	// Jitter randomizes each delay by up to this fraction of it in either direction,
+	// e.g. 0.1 makes a delay of 10s anywhere between 9s and 11s
+	Jitter float64
+}
+
+// delay returns the delay before the given retry, counted from 1
+func (p RetryPolicy) delay(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay < 0 || math.IsNaN(delay) {
+		return 0
+	}
+	if delay > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+// Retry sets the policy retrying the failed runs of the job, see `RetryPolicy`
+func (j *Job) Retry(policy RetryPolicy) *Job {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	j.state.retry = policy
+	return j
+}
+
+// retryPolicy returns the retry policy of the job
+func (j *Job) retryPolicy() RetryPolicy {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	return j.state.retry
+}
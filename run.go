@@ -0,0 +1,242 @@
+package gocron
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy decides what happens when a job is due while a previous run
+// of the job is still in progress
+type OverlapPolicy int
+
+const (
+	// OverlapAllow starts the new run alongside the previous one. It is the default policy
+	OverlapAllow OverlapPolicy = iota
+
+	// OverlapSkip drops the new run, and counts it in `Job.SkippedRuns`
+	OverlapSkip
+
+	// OverlapQueue starts the new run once the previous one has finished.
+	// Every run due in the meantime is queued
+	OverlapQueue
+
+	// OverlapReplace cancels the previous run and starts the new one.
+	// The tasks of the previous run that haven't been called yet are not called
+	OverlapReplace
+)
+
+// jobRun is a single in-progress run of a job
+type jobRun struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// the scheduler context the run was started with
+	parent context.Context
+
+	// the time the run was scheduled at
+	scheduled time.Time
+
+	// time of the next regular run when the run was due. Retries must start before it
+	deadline time.Time
+
+	// maximum duration of the run, started by `startTimeout`, and the func releasing its timer
+	timeout       time.Duration
+	cancelTimeout context.CancelFunc
+}
+
+// startTimeout starts the timeout of the run when its first attempt gets a worker,
+// so the time the run waited for a worker doesn't count
+func (r *jobRun) startTimeout() {
+	if r.timeout > 0 {
+		r.ctx, r.cancelTimeout = context.WithTimeout(r.ctx, r.timeout)
+	}
+}
+
+// runState tracks the in-progress runs of a job. It has its own lock since runs
+// start and finish on the workers, without holding the scheduler lock
+type runState struct {
+	mutex sync.Mutex
+
+	// what to do when the job is due while a run is in progress
+	overlap OverlapPolicy
+
+	// maximum duration of a run, 0 when unbounded
+	timeout time.Duration
+
+	// how failed runs are retried
+	retry RetryPolicy
+
+	// runs that have started and not finished yet
+	runs []*jobRun
+
+	// runs waiting for the in-progress run to finish, see `OverlapQueue`
+	queued []pendingRun
+
+	// number of runs dropped by `OverlapSkip`
+	skipped uint64
+
+	// number of runs dropped by `MisfireSkip`
+	missed uint64
+
+	// error of the last finished run, nil if it succeeded
+	lastErr error
+}
+
+// begin starts a new run of the job according to its overlap policy, with
+// a context derived from `parent`. It returns nil if the run was queued, or
+// skipped in which case it also returns true
+func (j *Job) begin(parent context.Context, scheduled, deadline time.Time) (*jobRun, bool) {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	if len(j.state.runs) > 0 {
+		switch j.state.overlap {
+		case OverlapSkip:
+			j.state.skipped++
+			return nil, true
+		case OverlapQueue:
+			j.state.queued = append(j.state.queued, pendingRun{job: j, scheduled: scheduled, deadline: deadline})
+			return nil, false
+		case OverlapReplace:
+			for _, r := range j.state.runs {
+				r.cancel()
+			}
+		}
+	}
+	return j.startRun(parent, scheduled, deadline), false
+}
+
+// finish ends a run of the job that failed with `err`, or succeeded if nil.
+// It returns the next queued run, if any
+func (j *Job) finish(r *jobRun, err error) (*jobRun, bool) {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	r.cancel()
+	if r.cancelTimeout != nil {
+		r.cancelTimeout()
+	}
+	j.state.lastErr = err
+	for i, run := range j.state.runs {
+		if run == r {
+			j.state.runs = append(j.state.runs[:i], j.state.runs[i+1:]...)
+			break
+		}
+	}
+
+	if len(j.state.queued) > 0 && len(j.state.runs) == 0 {
+		p := j.state.queued[0]
+		j.state.queued = j.state.queued[1:]
+		return j.startRun(r.parent, p.scheduled, p.deadline), true
+	}
+	return nil, false
+}
+
+// drop ends a run of the job that never started
+func (j *Job) drop(r *jobRun) {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	r.cancel()
+	for i, run := range j.state.runs {
+		if run == r {
+			j.state.runs = append(j.state.runs[:i], j.state.runs[i+1:]...)
+			break
+		}
+	}
+}
+
+// startRun registers a new in-progress run. The run state lock must be held
+func (j *Job) startRun(parent context.Context, scheduled, deadline time.Time) *jobRun {
+	r := &jobRun{parent: parent, scheduled: scheduled, deadline: deadline, timeout: j.state.timeout}
+	r.ctx, r.cancel = context.WithCancel(parent)
+	j.state.runs = append(j.state.runs, r)
+	return r
+}
+
+// cancelRuns cancels the context of every in-progress run, and drops the queued runs
+func (j *Job) cancelRuns() {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	for _, r := range j.state.runs {
+		r.cancel()
+	}
+	j.state.queued = nil
+}
+
+// dropQueued drops the runs waiting for the in-progress run to finish
+func (j *Job) dropQueued() {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	j.state.queued = nil
+}
+
+// Overlap sets what happens when the job is due while a previous run of the job
+// is still in progress. By default the runs overlap
+//
+// Example
+//
+//  // ...
+//	Every(1).Minute().Overlap(OverlapSkip).Do(task)  // skips a minute if the task takes longer than a minute
+//	Every(1).Minute().Overlap(OverlapQueue).Do(task) // runs the task once for every minute, one at a time
+//
+func (j *Job) Overlap(policy OverlapPolicy) *Job {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	j.state.overlap = policy
+	return j
+}
+
+// Timeout bounds the duration of each run of the job, including its retries. It starts when
+// the run gets a worker, see `WithMaxConcurrency`. When it elapses, the context passed to
+// the tasks taking one is cancelled and the remaining tasks of the run are not called
+//
+// Example
+//
+//  // ...
+//	Every(1).Minute().Timeout(30 * time.Second).Do(func(ctx context.Context) { ... }) // cancels each run after 30 seconds
+//
+func (j *Job) Timeout(d time.Duration) *Job {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	j.state.timeout = d
+	return j
+}
+
+// SkippedRuns returns the number of runs dropped because a previous run was still in progress
+func (j *Job) SkippedRuns() uint64 {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	return j.state.skipped
+}
+
+// setLastError records the error of a finished run
+func (j *Job) setLastError(err error) {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	j.state.lastErr = err
+}
+
+// LastError returns the error of the last finished run of the job, or nil if it succeeded.
+// The error is a `*PanicError` if a task panicked, or the error a task returned
+func (j *Job) LastError() error {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	return j.state.lastErr
+}
+
+// IsRunning returns true if a run of the job is in progress
+func (j *Job) IsRunning() bool {
+	j.state.mutex.Lock()
+	defer j.state.mutex.Unlock()
+
+	return len(j.state.runs) > 0
+}
@@ -0,0 +1,144 @@
+package gocron
+
+import "time"
+
+// Schedule computes the run times of a `Job`.
+//
+// Next returns the first time strictly after `after` at which the job should run.
+// `after` is given in the job's location, and the returned time should be too.
+// The job passes the time it was initialized at to get its first run, and the
+// time of its previous run to get every run after that.
+// Next returns the zero time when the job has no more runs. The job then never runs
+// again, and so does a job whose schedule returns a time that isn't after `after`.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule runs a job at a fixed interval, e.g. every 5 minutes
+type intervalSchedule struct {
+	every time.Duration
+}
+
+// Next returns `after` plus the interval
+func (s *intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(s.every)
+}
+
+// dailySchedule runs a job at each of `atTimes` every `interval` days
+type dailySchedule struct {
+	interval uint64
+	atTimes  []time.Duration
+}
+
+// Next returns the next of `atTimes` on the day of `after` if there is one left,
+// otherwise the first of `atTimes` `interval` days later
+func (s *dailySchedule) Next(after time.Time) time.Time {
+	if next, ok := nextAtTime(after, s.atTimes, after); ok {
+		return next
+	}
+	return atTimeOn(after.AddDate(0, 0, int(s.interval)), s.atTimes[0])
+}
+
+// weeklySchedule runs a job at each of `atTimes` on each of `weekDays`
+// every `interval` weeks. Weeks start on Sunday
+type weeklySchedule struct {
+	interval uint64
+	weekDays []time.Weekday
+	atTimes  []time.Duration
+}
+
+// Next returns the next slot in the week of `after` if there is one left,
+// otherwise the first slot `interval` weeks later
+func (s *weeklySchedule) Next(after time.Time) time.Time {
+	sunday := after.AddDate(0, 0, -int(after.Weekday()))
+	for _, weekDay := range s.weekDays {
+		if next, ok := nextAtTime(sunday.AddDate(0, 0, int(weekDay)), s.atTimes, after); ok {
+			return next
+		}
+	}
+	return atTimeOn(sunday.AddDate(0, 0, 7*int(s.interval)+int(s.weekDays[0])), s.atTimes[0])
+}
+
+// monthlySchedule runs a job at each of `atTimes` on a day of the month or quarter
+// every `interval` months or quarters
+type monthlySchedule struct {
+	interval uint64
+
+	// number of months in a period, 1 for monthly and 3 for quarterly jobs
+	period int
+
+	// day of the period, counted from the end of the period when negative,
+	// i.e. -1 is the last day of the month or quarter
+	monthDay int
+
+	// when not 0, the job runs on the nth `weekDay` of the period instead of
+	// `monthDay`, counted from the end of the period when negative
+	nthWeekday int
+	weekDay    time.Weekday
+
+	atTimes []time.Duration
+}
+
+// Next returns the next slot in the period of `after` if there is one left,
+// otherwise the first slot `interval` periods later
+func (s *monthlySchedule) Next(after time.Time) time.Time {
+	year, month, _ := after.Date()
+
+	// quarters start in January, April, July and October
+	start := month - (month-1)%time.Month(s.period)
+	if next, ok := nextAtTime(s.day(year, start, after.Location()), s.atTimes, after); ok {
+		return next
+	}
+	return atTimeOn(s.day(year, start+time.Month(s.period*int(s.interval)), after.Location()), s.atTimes[0])
+}
+
+// day returns the day the job runs on within the period starting at the given month.
+// Days past the end of a short month are clamped to its last day, so the 31st
+// runs on April 30th and February 28th or 29th
+func (s *monthlySchedule) day(year int, month time.Month, loc *time.Location) time.Time {
+	// normalize months past December
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	last := time.Date(year, month+time.Month(s.period), 0, 0, 0, 0, 0, loc)
+
+	switch {
+	case s.nthWeekday > 0:
+		offset := (int(s.weekDay) - int(first.Weekday()) + 7) % 7
+		return first.AddDate(0, 0, offset+7*(s.nthWeekday-1))
+	case s.nthWeekday < 0:
+		offset := (int(last.Weekday()) - int(s.weekDay) + 7) % 7
+		return last.AddDate(0, 0, -offset+7*(s.nthWeekday+1))
+	case s.monthDay < 0:
+		day := last.AddDate(0, 0, s.monthDay+1)
+		if day.Month() != last.Month() {
+			return time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		return day
+	default:
+		days := daysIn(first.Year(), first.Month())
+		if s.monthDay > days {
+			return first.AddDate(0, 0, days-1)
+		}
+		return first.AddDate(0, 0, s.monthDay-1)
+	}
+}
+
+// daysIn returns the number of days in the month, taking leap years into account
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// atTimeOn returns the wall clock time `atTime` on the day of `t`, in the location of `t`
+func atTimeOn(t time.Time, atTime time.Duration) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, int(atTime), t.Location())
+}
+
+// nextAtTime returns the first of the sorted `atTimes` on the day of `day` that is after `after`
+func nextAtTime(day time.Time, atTimes []time.Duration, after time.Time) (time.Time, bool) {
+	for _, atTime := range atTimes {
+		if next := atTimeOn(day, atTime); next.After(after) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
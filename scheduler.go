@@ -1,48 +1,126 @@
 package gocron
 
 import (
+	"container/heap"
+	"context"
 	"sort"
 	"sync"
 	"time"
 )
 
-// NewScheduler create a new scheduler.
-// Note: the current implementation is not concurrency safe.
-func NewScheduler() Scheduler {
-	return &scheduler{
-		jobMap:    make(map[string]*Job),
-		isStopped: make(chan bool),
-		location:  time.Local,
+// NewScheduler create a new scheduler configured by the given options.
+// The scheduler and its jobs are safe for concurrent use
+func NewScheduler(options ...Option) Scheduler {
+	s := &scheduler{
+		jobMap:   make(map[string]*Job),
+		active:   make(map[*Job]int),
+		location: time.Local,
+		clock:    systemClock{},
 	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
 // Scheduler contains jobs and a loop to run the jobs
 type scheduler struct {
 	jobMap    map[string]*Job
-	ejobs     []*Job // Emergency jobs
-	jobs      []*Job
+	ejobs     []*Job   // Emergency jobs
+	jobs      jobQueue // Jobs by next run
+	seq       uint64   // Number of jobs added, orders the jobs with the same next run
 	isRunning bool
-	isStopped chan bool
 	location  *time.Location
 	mutex     sync.Mutex
+
+	// closed by `Stop` to stop the run loop, which closes `stopped` when it returns
+	stop    chan struct{}
+	stopped chan struct{}
+
+	// timer of the run loop, armed for the next job while the scheduler is running
+	timer Timer
+
+	// source of the time the jobs run at, see `WithClock`
+	clock Clock
+
+	// persists the state of the named jobs, nil unless set by `WithStore`
+	store JobStore
+
+	// writes to the store queued while holding the mutex, see `flush`
+	writes []storeWrite
+
+	// serializes the writes to the store. It is never acquired while holding the mutex
+	storeMutex sync.Mutex
+
+	// maximum number of concurrent runs, 0 when unbounded
+	maxWorkers int
+
+	// number of worker goroutines, and the runs waiting for one of them,
+	// when the number of concurrent runs is bounded
+	workers int
+	backlog []*work
+
+	// runs dispatched to the workers that haven't finished yet
+	running sync.WaitGroup
+
+	// number of runs of each job dispatched to the workers that haven't finished yet
+	active map[*Job]int
+
+	// closed once the active runs have finished, while `Shutdown` waits for them
+	drained chan struct{}
+
+	// context the runs are derived from, cancelled by `Stop`
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// listeners notified of the lifecycle of the scheduler and its jobs
+	listeners []Listener
+
+	// events emitted while holding the mutex, not delivered to the listeners yet
+	events []event
+
+	// true while a goroutine delivers the events, see `deliver`
+	delivering bool
 }
 
-// Len returns the number of jobs that have been scheduled.
-// It is part of the `sort.Interface` interface
+// runContext returns the context the runs are derived from, creating it
+// after the scheduler was stopped. The mutex must be held
+func (s *scheduler) runContext() context.Context {
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	return s.ctx
+}
+
+// Len returns the number of jobs that have been scheduled
 func (s *scheduler) Len() int {
-	return len(s.jobs)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.jobs.Len()
 }
 
-// Swap swaps the order of two jobs in the backing slice.
-// It is part of the `sort.Interface` interface
-func (s *scheduler) Swap(i, j int) {
-	s.jobs[i], s.jobs[j] = s.jobs[j], s.jobs[i]
+// add adds a new job to the queue. The mutex must be held
+func (s *scheduler) add(job *Job) {
+	s.seq++
+	job.seq = s.seq
+	job.scheduler = s
+	// jobs built with their tasks are in sync with the run loop, like in `schedule`
+	if s.isRunning && job.err == nil && len(job.tasks) > 0 {
+		job.init(s.clock.Now())
+		s.persist(job)
+	}
+	s.jobs.add(job)
+	s.emit(func(l Listener) { l.JobAdded(job) })
+	s.rearm()
 }
 
-// Less swaps the order of two jobs in the backing slice.
-// It is part of the `sort.Interface` interface
-func (s *scheduler) Less(i, j int) bool {
-	return s.jobs[j].nextRun.After(s.jobs[i].nextRun)
+// Jobs returns the jobs of the scheduler, sorted by their next run
+func (s *scheduler) Jobs() []*Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.jobs.sorted()
 }
 
 // NextRun returns the job and time when the next job should run
@@ -53,201 +131,539 @@ func (s *scheduler) NextRun() (*Job, time.Time) {
 	if len(s.jobs) == 0 {
 		return nil, time.Time{}
 	}
-	sort.Sort(s)
+	if s.jobs[0].nextRun.Equal(never) {
+		return s.jobs[0], time.Time{}
+	}
 	return s.jobs[0], s.jobs[0].nextRun
 }
 
 // Every schedules a new job
 func (s *scheduler) Every(interval uint64) *Job {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	job := newJob(interval).Location(s.location)
-	s.jobs = append(s.jobs, job)
-
-	// inserion sort by 'interval'
-	for i := 1; i < len(s.jobs); i++ {
-		tmp := s.jobs[i]
-		j := i - 1
-		for j >= 0 && s.jobs[j].interval > tmp.interval {
-			s.jobs[j+1] = s.jobs[j]
-			j = j - 1
-		}
-		s.jobs[j+1] = tmp
-	}
+	s.add(job)
 
 	return job
 }
 
 // Add job name and job object to jobMap
 func (s *scheduler) EveryWithName(interval uint64, name string) *Job {
+	defer s.notify()
+	// load the state before locking, since the store may be slow
+	restored, err := s.restore(name)
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	// create/update job to job list and job map
+	job := newJob(interval).Location(s.location)
+	job.setName(name)
+	s.addNamed(job, restored, err)
+
+	return job
+}
+
+// addNamed adds a named job, replacing the job with the same name. The job resumes
+// from its state restored from the store, if any, or `err` is the error loading it.
+// The mutex must be held
+func (s *scheduler) addNamed(job *Job, restored *JobState, err error) {
 	// if job exist, remove it;
-	if oldJob, ok := s.jobMap[name]; ok {
+	if oldJob, ok := s.jobMap[job.name]; ok {
+		oldJob.cancelRuns()
+		s.emit(func(l Listener) { l.JobRemoved(oldJob) })
 		// we don't call s.Remove since it cause deadlock
-		for i, job := range s.jobs {
-			if oldJob == job {
-				copy(s.jobs[i:], s.jobs[i+1:])
-				s.jobs[len(s.jobs)-1] = nil
-				s.jobs = s.jobs[:len(s.jobs)-1]
-			}
-		}
+		s.jobs.remove(oldJob)
 	}
 
-	// create/update job to job list and job map
-	job := newJob(interval).Location(s.location)
-	s.jobMap[name] = job
-	s.jobs = append(s.jobs, job)
-
-	// inserion sort by 'interval'
-	for i := 1; i < len(s.jobs); i++ {
-		tmp := s.jobs[i]
-		j := i - 1
-		for j >= 0 && s.jobs[j].interval > tmp.interval {
-			s.jobs[j+1] = s.jobs[j]
-			j = j - 1
-		}
-		s.jobs[j+1] = tmp
+	job.restored = restored
+	if restored != nil && restored.Paused {
+		job.enabled = false
+	}
+	s.jobMap[job.name] = job
+	s.add(job)
+	if err != nil {
+		s.emit(func(l Listener) { l.StoreFailed(job, err) })
 	}
+}
+
+// Cron schedules a new job from a five-field (minute hour dom month dow) or
+// six-field (second minute hour dom month dow) cron expression, or one of the
+// `@yearly`, `@monthly`, `@weekly`, `@daily` and `@hourly` macros.
+// The expression is evaluated in the job's location.
+//
+// Example
+//
+//  // ...
+//	s.Cron("30 2 * * MON-FRI").Do(task)      // executes the task at 02:30 on weekdays
+//	s.Cron("*/10 * * * * *").Do(task)        // executes the task every 10 seconds
+//	s.Cron("@monthly").Location(est).Do(task) // executes the task at midnight on the 1st of each month
+//
+func (s *scheduler) Cron(expr string) *Job {
+	defer s.notify()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job := newJob(1).Location(s.location)
+	if cron, err := parseCron(expr); err != nil {
+		job.setError("Cron", expr, err)
+	} else {
+		job.schedule = cron
+	}
+	s.add(job)
 
 	return job
 }
 
 // Emergency schedules a new emergency job
 func (s *scheduler) Emergency() *Job {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	// cheat the interval
 	job := newJob(1).Location(s.location)
+	job.scheduler = s
 	s.ejobs = append(s.ejobs, job)
+	s.emit(func(l Listener) { l.JobAdded(job) })
 
 	return job
 }
 
-// runPending runs all of the jobs pending at this time
-func (s *scheduler) runPending(now time.Time) {
+// runPending dispatches all of the jobs pending at this time to the workers.
+// The mutex is only held while the pending jobs are collected and their next run
+// is computed, so slow tasks don't block the scheduler.
+// The returned wait group is done when all of the dispatched runs have finished
+func (s *scheduler) runPending(now time.Time) *sync.WaitGroup {
+	var pending []pendingRun
+
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	ctx := s.runContext()
 
-	// run emergency jobs
+	// run emergency jobs, keeping the ones `Do` wasn't called on yet
+	var ejobs []*Job
 	for _, job := range s.ejobs {
-		job.run()
+		if job.err != nil {
+			continue
+		}
+		if len(job.tasks) == 0 {
+			ejobs = append(ejobs, job)
+			continue
+		}
+		job.init(now)
+		job.advance()
+		pending = append(pending, pendingRun{job: job, scheduled: now})
 	}
-	// clear ejobs queue
-	s.ejobs = []*Job{}
+	s.ejobs = ejobs
 
-	sort.Sort(s)
+	// pop the jobs due at this time, including the new jobs which have no next run yet,
+	// and queue them again once their next run is computed, so each runs at most once
+	var due []*Job
+	for len(s.jobs) > 0 && !s.jobs[0].nextRun.After(now) {
+		due = append(due, heap.Pop(&s.jobs).(*Job))
+	}
 	// run jobs
-	for _, job := range s.jobs {
-		if !job.isInit() {
+	for _, job := range due {
+		switch {
+		case job.err != nil:
+			// never run jobs that were built with invalid arguments
+			job.nextRun = never
+		case len(job.tasks) == 0:
+			// set aside the jobs still being built until `Do` is called, see `schedule`
+			job.nextRun = never
+		case !job.isInit():
 			// set lastRun and nextRun
 			job.init(now)
+			s.persist(job)
+		case job.shouldRun(now):
+			runs := job.catchUp(now)
+			if len(runs) == 0 {
+				job := job
+				s.emit(func(l Listener) { l.RunSkipped(job) })
+			}
+			for _, scheduled := range runs {
+				pending = append(pending, pendingRun{job: job, scheduled: scheduled, deadline: job.nextRun})
+			}
+			s.persist(job)
+		default:
+			// paused jobs were initialized again by `shouldRun`
+			s.persist(job)
 		}
-		if job.shouldRun(now) {
-			job.run()
-		} else {
-			// intend to loop through
+		s.jobs.add(job)
+	}
+
+	s.mutex.Unlock()
+	s.flush()
+
+	dispatched := &sync.WaitGroup{}
+	for _, p := range pending {
+		s.dispatch(p, ctx, dispatched)
+	}
+
+	// sleep until the next job once the runs are dispatched
+	s.mutex.Lock()
+	s.rearm()
+	s.mutex.Unlock()
+	s.notify()
+	return dispatched
+}
+
+// pendingRun is a run collected by `runPending` to be dispatched once the lock is released
+type pendingRun struct {
+	job *Job
+
+	// the time the run was scheduled at
+	scheduled time.Time
+
+	// the next regular run of the job, zero for emergency jobs
+	deadline time.Time
+}
+
+// dispatch starts a run of the job according to its overlap policy, and submits it
+// to the workers. Runs the overlap policy of the job queues are submitted once the
+// previous run has finished
+func (s *scheduler) dispatch(p pendingRun, ctx context.Context, dispatched *sync.WaitGroup) {
+	job := p.job
+	r, skipped := job.begin(ctx, p.scheduled, p.deadline)
+	if r == nil {
+		if skipped {
+			s.mutex.Lock()
+			s.emit(func(l Listener) { l.RunSkipped(job) })
+			s.mutex.Unlock()
+		}
+		return
+	}
+
+	s.mutex.Lock()
+	s.active[job]++
+	s.mutex.Unlock()
+
+	s.running.Add(1)
+	dispatched.Add(1)
+	s.submit(&work{job: job, run: r, dispatched: dispatched})
+}
+
+// work is a run submitted to the workers, along with its queued runs
+type work struct {
+	job *Job
+	run *jobRun
+
+	// number of attempts of the run so far, and the time the first one started
+	attempts int
+	start    time.Time
+
+	// the task the next attempt starts from, the one the last attempt failed at
+	task int
+
+	// error of the last attempt
+	err error
+
+	// done once the run and the runs queued after it have finished
+	dispatched *sync.WaitGroup
+}
+
+// submit hands the run over to a worker. When the number of concurrent runs is
+// unbounded, each run has its own goroutine. Otherwise the run waits in the backlog
+// for one of at most `maxWorkers` worker goroutines, which are started on demand
+// and return once the backlog is empty
+func (s *scheduler) submit(w *work) {
+	if s.maxWorkers == 0 {
+		go s.process(w)
+		return
+	}
+
+	s.mutex.Lock()
+	s.backlog = append(s.backlog, w)
+	spawn := s.workers < s.maxWorkers
+	if spawn {
+		s.workers++
+	}
+	s.mutex.Unlock()
+
+	if spawn {
+		go s.worker()
+	}
+}
+
+// process executes the run and the runs queued after it on the goroutine of the run
+func (s *scheduler) process(w *work) {
+	for {
+		delay, retry := s.attempt(w)
+		if retry && s.wait(w, delay) {
+			continue
+		}
+		if !s.complete(w) {
+			return
+		}
+	}
+}
+
+// worker executes the runs of the backlog until it is empty. Workers are released
+// between attempts: a run waiting to be retried goes back to the backlog once its delay elapsed
+func (s *scheduler) worker() {
+	for {
+		s.mutex.Lock()
+		if len(s.backlog) == 0 {
+			s.workers--
+			s.mutex.Unlock()
+			return
+		}
+		w := s.backlog[0]
+		s.backlog[0] = nil
+		s.backlog = s.backlog[1:]
+		s.mutex.Unlock()
+
+		delay, retry := s.attempt(w)
+		if retry {
+			go func() {
+				if s.wait(w, delay) || s.complete(w) {
+					s.submit(w)
+				}
+			}()
+			continue
+		}
+		if s.complete(w) {
+			s.submit(w)
+		}
+	}
+}
+
+// attempt executes an attempt of the run. It returns true with the delay before the
+// next attempt if the run must be retried according to the retry policy of the job:
+// it failed, isn't cancelled, and the retry would start before the next regular run
+func (s *scheduler) attempt(w *work) (time.Duration, bool) {
+	job, r := w.job, w.run
+	if w.attempts == 0 {
+		s.publish(func(l Listener) { l.RunStarted(job, r.scheduled) })
+		w.start = s.clock.Now()
+		r.startTimeout()
+	}
+	w.attempts++
+	w.task, w.err = job.exec(r.ctx, w.task)
+
+	policy := job.retryPolicy()
+	if w.err == nil || w.attempts >= policy.MaxAttempts || r.ctx.Err() != nil {
+		return 0, false
+	}
+	delay := policy.delay(w.attempts)
+	if !r.deadline.IsZero() && !s.clock.Now().Add(delay).Before(r.deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
+// wait waits for the delay before the next attempt of the run.
+// It returns false if the run was cancelled in the meantime
+func (s *scheduler) wait(w *work, delay time.Duration) bool {
+	timer := s.clock.NewTimer(delay)
+	select {
+	case <-timer.C():
+		return true
+	case <-w.run.ctx.Done():
+		timer.Stop()
+		return false
+	}
+}
+
+// complete reports the outcome of the finished run to the listeners. It returns
+// true if the work continues with the next queued run of the job
+func (s *scheduler) complete(w *work) bool {
+	job, err := w.job, w.err
+	duration := s.clock.Now().Sub(w.start)
+	if err != nil {
+		s.publish(func(l Listener) { l.RunFailed(job, duration, err) })
+	} else {
+		s.publish(func(l Listener) { l.RunSucceeded(job, duration) })
+	}
+
+	if next, ok := job.finish(w.run, err); ok {
+		w.run, w.attempts, w.task, w.err = next, 0, 0, nil
+		return true
+	}
+	s.deactivate(job)
+	s.running.Done()
+	w.dispatched.Done()
+	return false
+}
+
+// dropBacklog drops the runs of the backlog that haven't started yet, and reports them
+// as skipped. The runs waiting for their next attempt are kept. The mutex must be held
+func (s *scheduler) dropBacklog() {
+	kept := s.backlog[:0]
+	for _, w := range s.backlog {
+		if w.attempts > 0 {
+			kept = append(kept, w)
 			continue
 		}
+		job := w.job
+		job.drop(w.run)
+		s.emit(func(l Listener) { l.RunSkipped(job) })
+		s.release(job)
+		s.running.Done()
+		w.dispatched.Done()
+	}
+	for i := len(kept); i < len(s.backlog); i++ {
+		s.backlog[i] = nil
+	}
+	s.backlog = kept
+}
+
+// deactivate records that a dispatched run of the job has finished
+func (s *scheduler) deactivate(job *Job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.release(job)
+}
+
+// release records that a dispatched run of the job has finished. The mutex must be held
+func (s *scheduler) release(job *Job) {
+	if s.active[job]--; s.active[job] == 0 {
+		delete(s.active, job)
+	}
+	if len(s.active) == 0 && s.drained != nil {
+		close(s.drained)
+		s.drained = nil
 	}
 }
 
-// Depricated: RunPending runs all of the jobs that are scheduled to run
+// Depricated: RunPending runs all of the jobs that are scheduled to run,
+// and waits for them to finish
 func (s *scheduler) RunPending() {
-	s.runPending(time.Now())
+	s.runPending(s.clock.Now()).Wait()
 }
 
 // Depricated: RunAll rungs all jobs regardless if they are scheduled to run or not
 func (s *scheduler) RunAll() {
-	RunAllWithDelay(0)
+	s.RunAllWithDelay(0)
 }
 
-// Depricated: RunAllWithDelay all jobs with delay seconds
+// Depricated: RunAllWithDelay all jobs with delay seconds, and waits for them to finish
 func (s *scheduler) RunAllWithDelay(d time.Duration) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	now := time.Now()
-	sort.Sort(s)
-	for _, job := range s.jobs {
+	ctx := s.runContext()
+	now := s.clock.Now()
+	var pending []pendingRun
+	for _, job := range s.jobs.sorted() {
+		if job.err != nil {
+			continue
+		}
 		if !job.isInit() {
 			// set lastRun and nextRun
 			job.init(now)
 		}
 		// force to run
-		job.run()
-		time.Sleep(d)
+		job.advance()
+		s.persist(job)
+		pending = append(pending, pendingRun{job: job, scheduled: now, deadline: job.nextRun})
+	}
+	heap.Init(&s.jobs)
+	s.rearm()
+	s.mutex.Unlock()
+	s.notify()
+
+	dispatched := &sync.WaitGroup{}
+	for _, p := range pending {
+		s.dispatch(p, ctx, dispatched)
+		if d > 0 {
+			<-s.clock.NewTimer(d).C()
+		}
 	}
+	s.notify()
+	dispatched.Wait()
+}
 
+// Clock returns the clock the scheduler reads the time from, see `WithClock`
+func (s *scheduler) Clock() Clock {
+	return s.clock
 }
 
 // Location sets the default location for every job created
 // with `Scheduler.Every(...)`. By default the location is `time.Local`
 func (s *scheduler) Location(location *time.Location) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	s.location = location
 }
 
-// Removes a job from the queue
+// Removes a job from the queue, and cancels its in-progress runs
 func (s *scheduler) Remove(j *Job) bool {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	for i, job := range s.jobs {
-		if j == job {
-			// fix potential memory leak problem arrcording to:
-			// https://github.com/golang/go/wiki/SliceTricks
-			copy(s.jobs[i:], s.jobs[i+1:])
-			s.jobs[len(s.jobs)-1] = nil
-			s.jobs = s.jobs[:len(s.jobs)-1]
-			return true
-		}
+	if s.jobs.remove(j) {
+		j.cancelRuns()
+		s.unpersist(j)
+		s.emit(func(l Listener) { l.JobRemoved(j) })
+		s.rearm()
+		return true
 	}
 
 	return false
 }
 
-// RemoveWithName removes an individual job from the scheduler by name
+// RemoveWithName removes an individual job from the scheduler by name,
+// and cancels its in-progress runs
 func (s *scheduler) RemoveWithName(name string) bool {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if dJob, ok := s.jobMap[name]; ok {
+	if job, ok := s.jobMap[name]; ok {
 		// we don't call s.Remove since it cause deadlock
-		for i, job := range s.jobs {
-			if dJob == job {
-				copy(s.jobs[i:], s.jobs[i+1:])
-				s.jobs[len(s.jobs)-1] = nil
-				s.jobs = s.jobs[:len(s.jobs)-1]
-				delete(s.jobMap, name) // remove jobMap item
-				return true
-			}
+		if s.jobs.remove(job) {
+			job.cancelRuns()
+			s.unpersist(job)
+			s.emit(func(l Listener) { l.JobRemoved(job) })
+			s.rearm()
+			delete(s.jobMap, name) // remove jobMap item
+			return true
 		}
 	}
 	return false
 }
 
-// UpdateIntervalWithName  update interval by name
-func (s *scheduler) UpdateIntervalWithName(name string, interval uint64) bool {
+// UpdateIntervalWithName  update interval by name.
+// An interval of 0, or a job whose schedule has no interval, leaves the job unchanged
+func (s *scheduler) UpdateIntervalWithName(name string, interval uint64) error {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if job, ok := s.jobMap[name]; ok {
-		job.updateInterval(interval)
-		return true
+	job, ok := s.jobMap[name]
+	switch {
+	case !ok:
+		return &JobError{Job: name, Op: "UpdateIntervalWithName", Arg: interval, Err: ErrJobNotFound}
+	case interval == 0:
+		return &JobError{Job: name, Op: "UpdateIntervalWithName", Arg: interval, Err: ErrIntervalNotValid}
+	case job.schedule != nil:
+		return &JobError{Job: name, Op: "UpdateIntervalWithName", Arg: interval, Err: ErrScheduleHasNoInterval}
 	}
-	return false
+
+	job.updateInterval(interval)
+	// the interval orders the jobs with the same next run
+	if s.jobs.contains(job) {
+		s.jobs.update(job)
+	}
+	s.rearm()
+	s.persist(job)
+	s.emit(func(l Listener) { l.JobIntervalUpdated(job, interval) })
+	return nil
 }
 
 // PauseWithName disable job by name
 func (s *scheduler) PauseWithName(name string) bool {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if job, ok := s.jobMap[name]; ok {
 		job.pause()
+		s.persist(job)
+		s.emit(func(l Listener) { l.JobPaused(job) })
 		return true
 	}
 	return false
@@ -255,21 +671,28 @@ func (s *scheduler) PauseWithName(name string) bool {
 
 // PauseAll disable all jobs
 func (s *scheduler) PauseAll() {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	for _, v := range s.jobMap {
 		v.pause()
+		s.persist(v)
+		job := v
+		s.emit(func(l Listener) { l.JobPaused(job) })
 	}
 }
 
 // ResumeWithName enable job by name
 func (s *scheduler) ResumeWithName(name string) bool {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if job, ok := s.jobMap[name]; ok {
 		job.resume()
+		s.persist(job)
+		s.emit(func(l Listener) { l.JobResumed(job) })
 		return true
 	}
 	return false
@@ -277,26 +700,40 @@ func (s *scheduler) ResumeWithName(name string) bool {
 
 // ResumeAll enable all jobs
 func (s *scheduler) ResumeAll() {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	for _, v := range s.jobMap {
 		v.resume()
+		s.persist(v)
+		job := v
+		s.emit(func(l Listener) { l.JobResumed(job) })
 	}
 }
 
-// Clear deletes all scheduled jobs
+// Clear deletes all scheduled jobs, and cancels their in-progress runs
 func (s *scheduler) Clear() {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.jobs = []*Job{}
+	for _, job := range s.jobs {
+		job.cancelRuns()
+		s.unpersist(job)
+		job.index = -1
+		job := job
+		s.emit(func(l Listener) { l.JobRemoved(job) })
+	}
+	s.jobs = jobQueue{}
+	s.rearm()
 	s.jobMap = make(map[string]*Job) // new job map
 }
 
-// Start all the pending jobs
-// Add seconds ticker
+// Start starts the run loop of the scheduler in a goroutine. The loop sleeps until
+// the next run of the earliest job, and is woken up when jobs are added, removed or updated
 func (s *scheduler) Start() {
+	defer s.notify()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -305,35 +742,96 @@ func (s *scheduler) Start() {
 		return
 	}
 
-	// start the scheduler
-	isStarted := make(chan bool)
-	ticker := time.NewTicker(200 * time.Millisecond)
-	go func() {
-		for {
-			select {
-			case now := <-ticker.C:
-				if !s.isRunning {
-					// initialize all of the jobs with the first ticker time
-					// so that they are all in sync with the run loop
-					for _, job := range s.jobs {
-						job.init(now)
-					}
-					s.isRunning = true
-					isStarted <- true
-				}
-				s.runPending(now)
-			case <-s.isStopped:
-				s.isRunning = false
-				// send a confirmation message back to the `Stop()` method
-				s.isStopped <- true
-				return
-			}
+	// initialize all of the jobs with the same time
+	// so that they are all in sync with the run loop
+	now := s.clock.Now()
+	for _, job := range s.jobs {
+		if job.err == nil && len(job.tasks) > 0 {
+			job.init(now)
+			s.persist(job)
 		}
-	}()
+	}
+	heap.Init(&s.jobs)
+
+	s.isRunning = true
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	s.timer = s.clock.NewTimer(0)
+	s.rearm()
+	go s.loop(s.timer.C(), s.stop, s.stopped)
+	s.emit(func(l Listener) { l.SchedulerStarted() })
+}
+
+// loop runs the pending jobs each time the timer fires, until `stop` is closed.
+// It closes `stopped` when it returns
+func (s *scheduler) loop(timer <-chan time.Time, stop, stopped chan struct{}) {
+	defer close(stopped)
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer:
+			s.runPending(s.clock.Now())
+		}
+	}
+}
 
-	// wait until he ticker has been started and all of the jobs
-	// have been initialized
-	<-isStarted
+// nextWakeup returns the time the loop must wake up at to run the next job,
+// or false if no job is pending. The mutex must be held
+func (s *scheduler) nextWakeup() (time.Time, bool) {
+	for _, job := range s.ejobs {
+		if len(job.tasks) > 0 {
+			return s.clock.Now(), true
+		}
+	}
+	if len(s.jobs) == 0 || s.jobs[0].nextRun.Equal(never) {
+		return time.Time{}, false
+	}
+	return s.jobs[0].nextRun, true
+}
+
+// rearm arms the timer of the run loop for the next job after the jobs changed,
+// or leaves it stopped when no job is pending. The mutex must be held
+func (s *scheduler) rearm() {
+	if s.timer == nil {
+		return
+	}
+	if !s.timer.Stop() {
+		// drop a time sent by the previous deadline that the loop didn't receive yet
+		select {
+		case <-s.timer.C():
+		default:
+		}
+	}
+	if next, ok := s.nextWakeup(); ok {
+		s.timer.Reset(next.Sub(s.clock.Now()))
+	}
+}
+
+// schedule schedules a job once `Do` is called on it. The run loop sets aside the
+// jobs without a task, and the job is initialized right away if the scheduler is running
+func (s *scheduler) schedule(job *Job) {
+	defer s.notify()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.jobs.contains(job) || job.isInit() {
+		s.rearm()
+		return
+	}
+	if job.schedule == nil && job.unit == 0 {
+		// the interval has no unit, e.g. `Every(1).Do(task)`
+		job.setError("Do", job.interval, ErrIntervalNotValid)
+	}
+	if s.isRunning && job.err == nil {
+		job.init(s.clock.Now())
+		s.persist(job)
+	} else {
+		job.nextRun = time.Time{}
+	}
+	s.jobs.update(job)
+	s.rearm()
 }
 
 // IsRunning returns true if the scheduler is startes
@@ -344,16 +842,101 @@ func (s *scheduler) IsRunning() bool {
 	return s.isRunning
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler, and cancels the context of the in-progress runs
 func (s *scheduler) Stop() {
+	defer s.notify()
+	running := s.halt()
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// only send the stop signal if the scheduler has been started
-	if s.isRunning {
-		s.isStopped <- true
-		// wait for the ticker to send a confirmation message back through
-		// the stop channel just before it shuts down the ticker loop
-		<-s.isStopped
+	s.terminate(running)
+}
+
+// Shutdown stops the scheduler from starting new runs, drops the queued runs, including
+// the ones waiting for a worker (see `WithMaxConcurrency`) which are reported as skipped,
+// and waits for the in-progress runs to finish. If `ctx` is done first, it cancels the
+// context of the runs and returns the jobs that were still running with the error of `ctx`
+//
+// Example
+//
+//  // ...
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if jobs, err := s.Shutdown(ctx); err != nil {
+//		log.Printf("%d jobs didn't finish: %v", len(jobs), err)
+//	}
+//
+func (s *scheduler) Shutdown(ctx context.Context) ([]*Job, error) {
+	defer s.notify()
+	running := s.halt()
+
+	s.mutex.Lock()
+	for job := range s.active {
+		job.dropQueued()
+	}
+	s.dropBacklog()
+	var drained chan struct{}
+	if len(s.active) > 0 {
+		if s.drained == nil {
+			s.drained = make(chan struct{})
+		}
+		drained = s.drained
+	}
+	s.mutex.Unlock()
+
+	var err error
+	if drained != nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var jobs []*Job
+	for job := range s.active {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].seq < jobs[k].seq })
+	s.terminate(running)
+	return jobs, err
+}
+
+// halt stops the run loop, and waits for it to return.
+// It returns true if the scheduler was running
+func (s *scheduler) halt() bool {
+	s.mutex.Lock()
+	stop, stopped := s.stop, s.stopped
+	running := s.isRunning
+	s.isRunning = false
+	s.stop, s.stopped = nil, nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mutex.Unlock()
+
+	// only stop the loop if the scheduler has been started, and wait for it to return.
+	// The lock isn't held meanwhile since the loop takes it to run the jobs
+	if running {
+		close(stop)
+		<-stopped
+	}
+	return running
+}
+
+// terminate cancels the context of the in-progress runs once the run loop
+// is stopped, `running` being true if it was running. The mutex must be held
+func (s *scheduler) terminate(running bool) {
+	if running {
+		s.emit(func(l Listener) { l.SchedulerStopped() })
+	}
+	if s.cancel != nil {
+		s.cancel()
+		s.ctx, s.cancel = nil, nil
 	}
 }
@@ -0,0 +1,237 @@
+package gocron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unitNames are the names of the units in the schedule specs
+var unitNames = map[time.Duration]string{
+	time.Second: "seconds",
+	time.Minute: "minutes",
+	time.Hour:   "hours",
+	Day:         "days",
+	Week:        "weeks",
+	monthly:     "months",
+	quarterly:   "quarters",
+}
+
+// spec describes the schedule of the job, e.g. "every 1 weeks on monday,friday at 9:00"
+// or "cron 30 2 * * MON-FRI". Jobs with a custom `Schedule` are described as "custom".
+// The scheduler lock must be held
+func (j *Job) spec() string {
+	if j.schedule != nil {
+		if cron, ok := j.schedule.(*cronSchedule); ok {
+			return "cron " + cron.expr
+		}
+		return "custom"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "every %d %s", j.interval, unitNames[j.unit])
+	switch {
+	case j.unit == Week:
+		names := make([]string, len(j.weekDays))
+		for i, weekDay := range j.weekDays {
+			names[i] = strings.ToLower(weekDay.String())
+		}
+		fmt.Fprintf(&b, " on %s", strings.Join(names, ","))
+	case j.nthWeekday != 0:
+		fmt.Fprintf(&b, " on weekday %d %s", j.nthWeekday, strings.ToLower(j.weekDays[0].String()))
+	case j.monthDay == -1:
+		b.WriteString(" on last day")
+	case j.monthDay < 0:
+		// -1 is the last day of the month internally, see `DayOfMonth`
+		fmt.Fprintf(&b, " on day %d", j.monthDay+1)
+	case j.monthDay > 0:
+		fmt.Fprintf(&b, " on day %d", j.monthDay)
+	}
+	if len(j.atTimes) > 0 && j.unit != time.Second && j.unit != time.Minute && j.unit != time.Hour {
+		times := make([]string, len(j.atTimes))
+		for i, atTime := range j.atTimes {
+			times[i] = formatAtTime(atTime)
+		}
+		fmt.Fprintf(&b, " at %s", strings.Join(times, ","))
+	}
+	return b.String()
+}
+
+// formatAtTime formats a time of day as "H:MM", or "H:MM:SS" if it has seconds
+func formatAtTime(atTime time.Duration) string {
+	hour, min, sec := int(atTime/time.Hour), int(atTime/time.Minute%60), int(atTime/time.Second%60)
+	if sec != 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hour, min, sec)
+	}
+	return fmt.Sprintf("%d:%02d", hour, min)
+}
+
+// specUnits are the builders of the units of the schedule specs
+var specUnits = map[string]func(*Job) *Job{
+	"second":   (*Job).Seconds,
+	"seconds":  (*Job).Seconds,
+	"minute":   (*Job).Minutes,
+	"minutes":  (*Job).Minutes,
+	"hour":     (*Job).Hours,
+	"hours":    (*Job).Hours,
+	"day":      (*Job).Days,
+	"days":     (*Job).Days,
+	"week":     (*Job).Weeks,
+	"weeks":    (*Job).Weeks,
+	"month":    (*Job).Months,
+	"months":   (*Job).Months,
+	"quarter":  (*Job).Quarters,
+	"quarters": (*Job).Quarters,
+}
+
+// Spec sets the schedule of the job from a spec, in the format the schedule of the jobs
+// is described in by `JobState` and `JobDefinition`:
+//
+//	every <interval> <unit> [on <days>] [at <times>]
+//	cron <expression>
+//
+// The unit is one of seconds, minutes, hours, days, weeks, months and quarters. Weekly jobs run
+// on a comma separated list of days of the week. Monthly and quarterly jobs run on a day of
+// the month "day <n>", "last day", or the nth weekday of the month "weekday <n> <day>", see
+// `DayOfMonth` and `NthWeekday`. Jobs running every day or more run at a comma separated list
+// of times of day, see `At`. The expression of cron jobs is passed to `Scheduler.Cron`
+//
+// Example
+//
+//  // ...
+//	s.EveryWithName(1, "report").Spec("every 1 weeks on monday,friday at 9:00").Do(task)
+//	s.EveryWithName(1, "backup").Spec("every 1 months on last day at 2:00").Do(task)
+//	s.EveryWithName(1, "poll").Spec("cron */10 * * * * *").Do(task)
+//
+func (j *Job) Spec(spec string) *Job {
+	fields := strings.Fields(spec)
+	if len(fields) > 0 && strings.ToLower(fields[0]) == "cron" {
+		cron, err := parseCron(strings.TrimSpace(strings.TrimSpace(spec)[len(fields[0]):]))
+		if err != nil {
+			j.recordError("Spec", spec, err)
+			return j
+		}
+		return j.Schedule(cron)
+	}
+
+	invalid := func(format string, args ...interface{}) *Job {
+		j.recordError("Spec", spec, fmt.Errorf("%w: "+format, append([]interface{}{ErrSpecNotValid}, args...)...))
+		return j
+	}
+	if len(fields) < 3 || strings.ToLower(fields[0]) != "every" {
+		return invalid("expected \"every <interval> <unit>\" or \"cron <expression>\"")
+	}
+	interval, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil || interval == 0 {
+		return invalid("the interval %q is not a positive integer", fields[1])
+	}
+	unitName := strings.ToLower(fields[2])
+	unit, ok := specUnits[unitName]
+	if !ok {
+		return invalid("unknown unit %q", fields[2])
+	}
+
+	// split the "on" and "at" clauses
+	var on, at []string
+	rest := fields[3:]
+	for len(rest) > 0 {
+		keyword := strings.ToLower(rest[0])
+		end := 1
+		for end < len(rest) && !isSpecKeyword(rest[end]) {
+			end++
+		}
+		switch {
+		case keyword == "on" && on == nil && at == nil && end > 1:
+			on = rest[1:end]
+		case keyword == "at" && at == nil && end > 1:
+			at = strings.Split(strings.Join(rest[1:end], ""), ",")
+		default:
+			return invalid("unexpected %q", strings.Join(rest, " "))
+		}
+		rest = rest[end:]
+	}
+
+	unlock := j.lock()
+	j.updateInterval(interval)
+	unlock()
+
+	switch strings.TrimSuffix(unitName, "s") {
+	case "week":
+		if on == nil {
+			// the day of the week the job is created on
+			unit(j)
+			break
+		}
+		for _, name := range strings.Split(strings.Join(on, ""), ",") {
+			weekday, ok := parseWeekday(name)
+			if !ok {
+				return invalid("unknown day of the week %q", name)
+			}
+			j.Weekday(weekday)
+		}
+	case "month", "quarter":
+		unit(j)
+		if on != nil && !j.onDay(on) {
+			return invalid("expected \"day <n>\", \"last day\" or \"weekday <n> <day>\", found %q", strings.Join(on, " "))
+		}
+	default:
+		if on != nil {
+			return invalid("jobs running every %s have no days", unitName)
+		}
+		unit(j)
+	}
+
+	if at != nil {
+		if unitName == "second" || unitName == "seconds" || unitName == "minute" || unitName == "minutes" ||
+			unitName == "hour" || unitName == "hours" {
+			return invalid("jobs running every %s have no times of day", unitName)
+		}
+		j.At(at...)
+	}
+	return j
+}
+
+// isSpecKeyword returns true if the field starts a clause of a schedule spec
+func isSpecKeyword(field string) bool {
+	field = strings.ToLower(field)
+	return field == "on" || field == "at"
+}
+
+// onDay applies the "on" clause of a monthly or quarterly spec.
+// It returns false if the clause isn't valid
+func (j *Job) onDay(on []string) bool {
+	switch {
+	case len(on) == 2 && strings.ToLower(on[0]) == "last" && strings.ToLower(on[1]) == "day":
+		j.LastDayOfMonth()
+	case len(on) == 2 && strings.ToLower(on[0]) == "day":
+		day, err := strconv.Atoi(on[1])
+		if err != nil {
+			return false
+		}
+		j.DayOfMonth(day)
+	case len(on) == 3 && strings.ToLower(on[0]) == "weekday":
+		n, err := strconv.Atoi(on[1])
+		weekday, ok := parseWeekday(on[2])
+		if err != nil || !ok {
+			return false
+		}
+		j.NthWeekday(n, weekday)
+	default:
+		return false
+	}
+	return true
+}
+
+// parseWeekday parses the full or three-letter english name of a day of the week
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+	if len(name) < 3 {
+		return 0, false
+	}
+	weekday, ok := weekdayNames[name[:3]]
+	if !ok || (len(name) > 3 && name != strings.ToLower(time.Weekday(weekday).String())) {
+		return 0, false
+	}
+	return time.Weekday(weekday), true
+}
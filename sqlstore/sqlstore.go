@@ -0,0 +1,358 @@
+// Package sqlstore is a `gocron.JobStore` embedded in a SQLite database. It persists
+// the state, the schedule and the definition of the named jobs, and records the history
+// of their runs. It uses a pure-Go SQLite driver, so it needs neither cgo nor a database server.
+//
+// Example
+//
+//  // ...
+//	store, err := sqlstore.Open("/var/lib/myservice/jobs.db", sqlstore.Retention(30*24*time.Hour))
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//	s := gocron.NewScheduler(gocron.WithStore(store)) // records every run of the jobs
+//	s.EveryWithName(1, "backup").Day().At("02:00").DoTask("backup")
+//
+//	// ... after a restart, the jobs running registered tasks are added again from the store
+//	states, err := store.Jobs()
+//	for _, state := range states {
+//		if state.Definition != nil {
+//			s.AddDefinition(*state.Definition)
+//		}
+//	}
+//
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/taka-wang/gocron"
+
+	// registers the "sqlite" driver
+	_ "modernc.org/sqlite"
+)
+
+// schema creates the tables of the store. Times are stored as Unix nanoseconds,
+// and the definitions of the jobs as JSON, empty for the jobs without one
+const schema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	name       TEXT PRIMARY KEY,
+	schedule   TEXT NOT NULL DEFAULT '',
+	location   TEXT NOT NULL DEFAULT '',
+	enabled    INTEGER NOT NULL DEFAULT 1,
+	last_run   INTEGER,
+	next_run   INTEGER,
+	definition TEXT NOT NULL DEFAULT '',
+	updated    INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS runs (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	job      TEXT NOT NULL,
+	started  INTEGER NOT NULL,
+	finished INTEGER NOT NULL,
+	outcome  TEXT NOT NULL,
+	error    TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS runs_job ON runs (job, id);
+CREATE INDEX IF NOT EXISTS runs_finished ON runs (finished);
+`
+
+// pruneInterval is the minimum delay between two prunings of the run history by `Retention`
+const pruneInterval = time.Minute
+
+// Outcome is the outcome of a run
+type Outcome string
+
+const (
+	// Succeeded is the outcome of the runs whose tasks all succeeded
+	Succeeded Outcome = "succeeded"
+
+	// Failed is the outcome of the runs whose task failed, see `gocron.Job.LastError`
+	Failed Outcome = "failed"
+
+	// Skipped is the outcome of the runs dropped by the overlap or misfire policy of the job
+	Skipped Outcome = "skipped"
+)
+
+// Run is a run of a job recorded in the history
+type Run struct {
+	ID       int64
+	Job      string
+	Started  time.Time
+	Finished time.Time
+	Outcome  Outcome
+
+	// Error is the error of the failed runs, empty otherwise
+	Error string
+}
+
+// Store is a `gocron.JobStore` kept in a SQLite database. It is a `gocron.Listener`
+// recording the runs of the jobs, added to the listeners of the scheduler by `gocron.WithStore`
+type Store struct {
+	gocron.NopListener
+
+	db *sql.DB
+
+	// how long runs are kept in the history, 0 to keep them forever
+	retention time.Duration
+
+	// called with the errors recording the runs, which listeners can't return
+	onError func(error)
+
+	// source of the time the runs finish at, the system clock when nil
+	clock gocron.Clock
+
+	mutex  sync.Mutex
+	pruned time.Time
+}
+
+// Option configures a store created by `Open` or `New`
+type Option func(*Store)
+
+// Retention deletes the runs that finished more than `d` ago from the history,
+// at most once a minute when runs are recorded. By default runs are kept forever
+func Retention(d time.Duration) Option {
+	return func(s *Store) {
+		s.retention = d
+	}
+}
+
+// OnError sets the function called with the errors recording the runs.
+// By default they are dropped
+func OnError(fn func(error)) Option {
+	return func(s *Store) {
+		s.onError = fn
+	}
+}
+
+// Clock sets the clock the times of the runs are read from. Pass the clock of the scheduler
+// if it isn't the system clock, see `gocron.WithClock`. By default it is the system clock
+func Clock(clock gocron.Clock) Option {
+	return func(s *Store) {
+		s.clock = clock
+	}
+}
+
+// Open opens the store kept in the SQLite database at `path`, creating it if needed
+func Open(path string, options ...Option) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, so the connections would only wait for each other
+	db.SetMaxOpenConns(1)
+
+	s, err := New(db, options...)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New creates a store in the database `db`, creating its tables if needed
+func New(db *sql.DB, options ...Option) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	s := &Store{db: db}
+	for _, option := range options {
+		option(s)
+	}
+	return s, nil
+}
+
+// Close closes the database of the store
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// stateColumns are the columns of the jobs table scanned by `scanState`
+const stateColumns = `name, schedule, location, enabled, last_run, next_run, definition`
+
+// Load returns the state of the job
+func (s *Store) Load(name string) (gocron.JobState, bool, error) {
+	state, err := scanState(s.db.QueryRow(`SELECT `+stateColumns+` FROM jobs WHERE name = ?`, name))
+	if err == sql.ErrNoRows {
+		return gocron.JobState{Name: name}, false, nil
+	}
+	if err != nil {
+		return state, false, err
+	}
+	return state, true, nil
+}
+
+// Save saves the state of the job
+func (s *Store) Save(state gocron.JobState) error {
+	definition := ""
+	if state.Definition != nil {
+		data, err := json.Marshal(state.Definition)
+		if err != nil {
+			return err
+		}
+		definition = string(data)
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO jobs (name, schedule, location, enabled, last_run, next_run, definition, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET schedule = excluded.schedule, location = excluded.location,
+			enabled = excluded.enabled, last_run = excluded.last_run, next_run = excluded.next_run,
+			definition = excluded.definition, updated = excluded.updated`,
+		state.Name, state.Schedule, state.Location, !state.Paused, nanos(state.LastRun), nanos(state.NextRun), definition, s.now().UnixNano())
+	return err
+}
+
+// Delete deletes the state of the job. The runs of the job are kept in the history
+func (s *Store) Delete(name string) error {
+	_, err := s.db.Exec(`DELETE FROM jobs WHERE name = ?`, name)
+	return err
+}
+
+// Jobs returns the states of the jobs in the store, sorted by name
+func (s *Store) Jobs() ([]gocron.JobState, error) {
+	rows, err := s.db.Query(`SELECT ` + stateColumns + ` FROM jobs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []gocron.JobState
+	for rows.Next() {
+		state, err := scanState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+// scanState scans the `stateColumns` of a row of the jobs table
+func scanState(row interface{ Scan(...interface{}) error }) (gocron.JobState, error) {
+	var state gocron.JobState
+	var enabled bool
+	var lastRun, nextRun sql.NullInt64
+	var definition string
+	if err := row.Scan(&state.Name, &state.Schedule, &state.Location, &enabled, &lastRun, &nextRun, &definition); err != nil {
+		return state, err
+	}
+	state.Paused = !enabled
+	state.LastRun = fromNanos(lastRun)
+	state.NextRun = fromNanos(nextRun)
+	if definition != "" {
+		state.Definition = &gocron.JobDefinition{}
+		if err := json.Unmarshal([]byte(definition), state.Definition); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+// Runs returns the last `limit` runs of the job recorded in the history, most recent first.
+// All of the runs are returned if `limit` is 0
+func (s *Store) Runs(job string, limit int) ([]Run, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.Query(`
+		SELECT id, job, started, finished, outcome, error FROM runs
+		WHERE job = ? ORDER BY id DESC LIMIT ?`, job, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var r Run
+		var started, finished int64
+		if err := rows.Scan(&r.ID, &r.Job, &started, &finished, &r.Outcome, &r.Error); err != nil {
+			return nil, err
+		}
+		r.Started = time.Unix(0, started)
+		r.Finished = time.Unix(0, finished)
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// Prune deletes the runs that finished before `before` from the history.
+// It returns the number of deleted runs
+func (s *Store) Prune(before time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM runs WHERE finished < ?`, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RunSucceeded records the succeeded run
+func (s *Store) RunSucceeded(job *gocron.Job, duration time.Duration) {
+	s.record(job, duration, Succeeded, "")
+}
+
+// RunFailed records the failed run and its error
+func (s *Store) RunFailed(job *gocron.Job, duration time.Duration, err error) {
+	s.record(job, duration, Failed, err.Error())
+}
+
+// RunSkipped records the skipped run
+func (s *Store) RunSkipped(job *gocron.Job) {
+	s.record(job, 0, Skipped, "")
+}
+
+// record inserts a run that just finished in the history, and prunes the history.
+// Like their state, the runs of the jobs without a name aren't kept
+func (s *Store) record(job *gocron.Job, duration time.Duration, outcome Outcome, errText string) {
+	if job.Name() == "" {
+		return
+	}
+	finished := s.now()
+	_, err := s.db.Exec(`INSERT INTO runs (job, started, finished, outcome, error) VALUES (?, ?, ?, ?, ?)`,
+		job.Name(), finished.Add(-duration).UnixNano(), finished.UnixNano(), string(outcome), errText)
+	if err == nil && s.retention > 0 && s.shouldPrune(finished) {
+		_, err = s.Prune(finished.Add(-s.retention))
+	}
+	if err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// now returns the current time according to the clock of the store
+func (s *Store) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+// shouldPrune returns true if the history wasn't pruned for a minute
+func (s *Store) shouldPrune(now time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.pruned) < pruneInterval {
+		return false
+	}
+	s.pruned = now
+	return true
+}
+
+// nanos returns the time as Unix nanoseconds, or NULL if it is zero
+func nanos(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UnixNano()
+}
+
+// fromNanos returns the time of Unix nanoseconds, or the zero time if NULL
+func fromNanos(n sql.NullInt64) time.Time {
+	if !n.Valid {
+		return time.Time{}
+	}
+	return time.Unix(0, n.Int64)
+}
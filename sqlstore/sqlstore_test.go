@@ -0,0 +1,186 @@
+// Tests for the SQLite store of gocron
+package sqlstore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/taka-wang/gocron"
+	"github.com/taka-wang/gocron/internal/sugar"
+)
+
+// finished is a listener signaling the finished runs. Listeners are notified in order,
+// so the store added before it has recorded a run when it is signaled
+type finished struct {
+	gocron.NopListener
+	runs chan bool
+}
+
+func (f finished) RunSucceeded(job *gocron.Job, d time.Duration)         { f.runs <- true }
+func (f finished) RunFailed(job *gocron.Job, d time.Duration, err error) { f.runs <- true }
+
+// wait waits for `n` runs to finish
+func (f finished) wait(n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-f.runs:
+		case <-time.After(5 * time.Second):
+			return
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+
+	s := sugar.New(t)
+
+	s.Title("SQLite store")
+
+	dir, err := os.MkdirTemp("", "sqlstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s.Assert("the store saves, loads and deletes the state of the jobs", func(log sugar.Log) bool {
+		store, err := Open(":memory:")
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		defer store.Close()
+
+		last := time.Date(2016, time.January, 6, 10, 0, 0, 0, time.UTC)
+		store.Save(gocron.JobState{Name: "b", LastRun: last, NextRun: last.Add(time.Hour), Schedule: "every 1 hours"})
+		store.Save(gocron.JobState{Name: "a", Schedule: "every 1 days at 10:30", Paused: true})
+		store.Save(gocron.JobState{Name: "b", LastRun: last.Add(time.Hour), NextRun: last.Add(2 * time.Hour), Schedule: "every 1 hours"})
+
+		b, ok, err := store.Load("b")
+		if err != nil || !ok || !b.LastRun.Equal(last.Add(time.Hour)) || !b.NextRun.Equal(last.Add(2*time.Hour)) || b.Paused {
+			log("%v %v %v", b, ok, err)
+			return false
+		}
+		jobs, err := store.Jobs()
+		if err != nil || len(jobs) != 2 || jobs[0].Name != "a" || !jobs[0].Paused || !jobs[0].LastRun.IsZero() || jobs[0].Schedule != "every 1 days at 10:30" {
+			log("%v %v", jobs, err)
+			return false
+		}
+
+		store.Delete("a")
+		_, ok, err = store.Load("a")
+		return !ok && err == nil
+	})
+
+	s.Assert("the store saves the definition of the jobs", func(log sugar.Log) bool {
+		store, err := Open(":memory:")
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		defer store.Close()
+
+		def := gocron.JobDefinition{
+			Name:     "backup",
+			Schedule: "every 1 days at 2:00",
+			Location: "Asia/Taipei",
+			Tasks:    []gocron.TaskDefinition{{Name: "backup", Args: []json.RawMessage{[]byte(`"/var/data"`)}}},
+			Timeout:  time.Minute,
+			Tags:     []string{"nightly"},
+		}
+		store.Save(gocron.JobState{Name: "backup", Schedule: def.Schedule, Definition: &def})
+		store.Save(gocron.JobState{Name: "plain", Schedule: "every 1 hours"})
+
+		backup, _, err := store.Load("backup")
+		plain, _, _ := store.Load("plain")
+		log("%v %v %v", backup.Definition, plain.Definition, err)
+		return err == nil && backup.Definition != nil && reflect.DeepEqual(*backup.Definition, def) && plain.Definition == nil
+	})
+
+	s.Assert("the store records the runs of the named jobs on the clock of the scheduler", func(log sugar.Log) bool {
+		clock := gocron.NewFakeClock(time.Date(2016, time.January, 6, 10, 0, 0, 0, time.Local))
+		store, err := Open(":memory:", Clock(clock))
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		defer store.Close()
+
+		scheduler := gocron.NewScheduler(gocron.WithClock(clock), gocron.WithStore(store))
+		runs := finished{runs: make(chan bool, 10)}
+		scheduler.AddListener(runs)
+		scheduler.EveryWithName(1, "ok").Second().Do(func() {})
+		scheduler.Every(1).Second().Do(func() {})
+		scheduler.EveryWithName(1, "bad").Second().Do(func() error { return errors.New("oops") })
+
+		// the first call initializes the jobs
+		scheduler.RunPending()
+		for i := 0; i < 3; i++ {
+			clock.Advance(time.Second)
+			scheduler.RunPending()
+			runs.wait(3)
+		}
+
+		ok, _ := store.Runs("ok", 0)
+		bad, _ := store.Runs("bad", 2)
+		unnamed, _ := store.Runs("", 0)
+		state, _, _ := store.Load("ok")
+		log("%v %v %v", ok, bad, state)
+		return len(ok) == 3 && ok[0].Outcome == Succeeded && ok[0].ID > ok[1].ID && len(unnamed) == 0 &&
+			ok[0].Finished.Equal(clock.Now()) && ok[0].Started.Equal(clock.Now()) &&
+			len(bad) == 2 && bad[0].Outcome == Failed && bad[0].Error == "oops" &&
+			state.Schedule == "every 1 seconds" && state.Location == time.Local.String() && state.NextRun.Equal(clock.Now().Add(time.Second))
+	})
+
+	s.Assert("a restarted scheduler resumes the paused jobs", func(log sugar.Log) bool {
+		path := filepath.Join(dir, "restart.db")
+		store, err := Open(path)
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		scheduler := gocron.NewScheduler(gocron.WithStore(store))
+		scheduler.EveryWithName(1, "job").Day().Do(func() {})
+		scheduler.PauseWithName("job")
+		store.Close()
+
+		store, err = Open(path)
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		defer store.Close()
+		scheduler = gocron.NewScheduler(gocron.WithStore(store))
+		job := scheduler.EveryWithName(1, "job").Day().Do(func() {})
+		return job.IsPaused()
+	})
+
+	s.Assert("`Prune(...)` and `Retention(...)` delete the old runs", func(log sugar.Log) bool {
+		store, err := Open(":memory:", Retention(time.Hour))
+		if err != nil {
+			log("%v", err)
+			return false
+		}
+		defer store.Close()
+
+		old := time.Now().Add(-2 * time.Hour).UnixNano()
+		for i := 0; i < 3; i++ {
+			store.db.Exec(`INSERT INTO runs (job, started, finished, outcome) VALUES ('job', ?, ?, 'succeeded')`, old, old)
+		}
+		// recording a run prunes the runs older than the retention
+		store.RunSkipped(gocron.NewScheduler().EveryWithName(1, "skipped"))
+		pruned, _ := store.Runs("job", 0)
+		runs, _ := store.Runs("skipped", 0)
+		if len(pruned) != 0 || len(runs) != 1 || runs[0].Outcome != Skipped {
+			log("%v %v", pruned, runs)
+			return false
+		}
+
+		n, err := store.Prune(time.Now().Add(time.Hour))
+		return n == 1 && err == nil
+	})
+}
@@ -0,0 +1,158 @@
+package gocron
+
+import "time"
+
+// JobState is the state of a named job kept by a `JobStore`
+type JobState struct {
+	// Name is the name of the job, see `EveryWithName`
+	Name string `json:"name"`
+
+	// LastRun is the time the last run of the job was scheduled at
+	LastRun time.Time `json:"last_run"`
+
+	// NextRun is the time the next run of the job is scheduled at
+	NextRun time.Time `json:"next_run"`
+
+	// Schedule describes the schedule of the job, e.g. "every 1 days at 10:30" or "cron @hourly"
+	Schedule string `json:"schedule,omitempty"`
+
+	// Location is the name of the location the schedule of the job is computed in, e.g. "Asia/Taipei"
+	Location string `json:"location,omitempty"`
+
+	// Paused is true if the job was paused by `PauseWithName` or `PauseAll`.
+	// A restored job stays paused until it is resumed
+	Paused bool `json:"paused,omitempty"`
+
+	// Definition is the definition of the job, nil if the job can't be defined, e.g. because
+	// its tasks were passed to `Do` instead of `DoTask`. A restarted process can add the
+	// saved jobs again from their definition with `AddDefinition`
+	Definition *JobDefinition `json:"definition,omitempty"`
+}
+
+// JobStore persists the state of the named jobs of a scheduler, so a restarted process
+// resumes each job where it left off. The scheduler loads the state of a job when it is
+// created by `EveryWithName`, saves it whenever the next run of the job is computed or
+// the job is paused, resumed or updated, and deletes it when the job is removed.
+//
+// The scheduler never calls the store while holding its lock, and serializes its
+// calls to the store. Failures are reported to the `StoreFailed` listeners
+type JobStore interface {
+	// Load returns the state of the job, or false if the store has no state for it
+	Load(name string) (JobState, bool, error)
+
+	// Save saves the state of the job, replacing its previous state
+	Save(state JobState) error
+
+	// Delete deletes the state of the job, if any
+	Delete(name string) error
+}
+
+// batcher is a `JobStore` making the writes between `begin` and `commit` at once,
+// e.g. `FileStore` which rewrites its whole file on each write
+type batcher interface {
+	begin()
+	commit() error
+}
+
+// storeWrite is a write to the store queued while holding the mutex, see `flush`
+type storeWrite struct {
+	job   *Job
+	write func(JobStore) error
+}
+
+// restore loads the state of the job named `name` from the store.
+// It returns nil if there is no store or no state. The mutex must not be held
+func (s *scheduler) restore(name string) (*JobState, error) {
+	if s.store == nil {
+		return nil, nil
+	}
+	state, ok, err := s.store.Load(name)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// persist queues a write of the state of a named job to the store. The mutex must be held
+func (s *scheduler) persist(job *Job) {
+	if s.store == nil || job.name == "" {
+		return
+	}
+	state := JobState{
+		Name:     job.name,
+		LastRun:  job.lastRun,
+		NextRun:  job.nextRun,
+		Schedule: job.spec(),
+		Location: job.location.String(),
+		Paused:   !job.enabled,
+	}
+	if def, err := job.definition(); err == nil {
+		state.Definition = &def
+	}
+	if state.NextRun.Equal(never) {
+		// the job has no more runs, which its schedule says again when it is restored
+		state.NextRun = time.Time{}
+	}
+	s.writes = append(s.writes, storeWrite{job, func(store JobStore) error {
+		return store.Save(state)
+	}})
+}
+
+// unpersist queues the deletion of the state of a named job from the store. The mutex must be held
+func (s *scheduler) unpersist(job *Job) {
+	if s.store == nil || job.name == "" {
+		return
+	}
+	name := job.name
+	s.writes = append(s.writes, storeWrite{job, func(store JobStore) error {
+		return store.Delete(name)
+	}})
+}
+
+// flush makes the writes to the store queued so far, in order, and at once if the store
+// batches them, see `batcher`. The mutex must not be held
+func (s *scheduler) flush() {
+	if s.store == nil {
+		return
+	}
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	s.mutex.Lock()
+	writes := s.writes
+	s.writes = nil
+	s.mutex.Unlock()
+
+	batch, batched := s.store.(batcher)
+	if batched && len(writes) > 1 {
+		batch.begin()
+	} else {
+		batched = false
+	}
+	for _, w := range writes {
+		if err := w.write(s.store); err != nil {
+			s.storeFailed(w.job, err)
+		}
+	}
+	if !batched {
+		return
+	}
+	if err := batch.commit(); err != nil {
+		// the writes of every job of the batch failed
+		failed := make(map[*Job]bool)
+		for _, w := range writes {
+			if !failed[w.job] {
+				failed[w.job] = true
+				s.storeFailed(w.job, err)
+			}
+		}
+	}
+}
+
+// storeFailed reports the failure of a write of the state of the job. The mutex must not be held
+func (s *scheduler) storeFailed(job *Job, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.emit(func(l Listener) { l.StoreFailed(job, err) })
+}